@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/service"
+	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/FactomProject/factom"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+
+	var err error
+	var action, chainID, param string
+
+	var conf *config.Config
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	configFile := usr.HomeDir + "/.foa/config.yaml"
+
+	flag.StringVar(&configFile, "c", configFile, "config.yaml path")
+	flag.Parse()
+
+	if conf, err = config.NewConfig(configFile); err != nil {
+		log.Fatal(err)
+	}
+
+	args := flag.Args()
+
+	if len(args) == 0 {
+		log.Fatal("No params provided")
+	}
+
+	if len(args) >= 1 {
+		action = args[0]
+	}
+
+	if len(args) >= 2 {
+		chainID = args[1]
+	}
+
+	if len(args) >= 3 {
+		param = args[2]
+	}
+
+	log.Info("action=", action, ", chainId=", chainID, ", param=", param)
+
+	if chainID == "" && action != "help" {
+		log.Fatal("ChainID can not be null for action ", action)
+	}
+
+	store, err := store.NewStore(conf, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer store.Close()
+
+	// verify may re-fetch data from factomd
+	if conf.Factom.URL != "" {
+		factom.SetFactomdServer(conf.Factom.URL)
+	}
+	if conf.Factom.User != "" && conf.Factom.Password != "" {
+		factom.SetFactomdRpcConfig(conf.Factom.User, conf.Factom.Password)
+	}
+
+	// chain tools don't write on the blockchain, so no wallet needed
+	s := service.NewService(conf, store, nil)
+
+	chain := &model.Chain{ChainID: chainID}
+
+	switch action {
+	case "help":
+
+		fmt.Printf("Chain management tool for Factom Open API:\n")
+		fmt.Printf("chain help — Show help\n")
+		fmt.Printf("chain import <chainId> chain.ndjson — Import chain into local DB from NDJSON archive, made by chain export\n")
+		fmt.Printf("chain verify <chainId> — Check integrity of local chain data\n")
+		fmt.Printf("chain verify <chainId> repair — Check integrity of local chain data & re-fetch missing or corrupted data from Factom\n")
+
+	case "import":
+
+		if param == "" {
+			log.Fatal("You have to provide a path to NDJSON archive for action ", action)
+		}
+
+		archive, err := os.Open(param)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer archive.Close()
+
+		result, err := s.ImportChain(chain, archive)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Info("Chain ", result.ChainID, " imported: eblocks=", result.EBlocks, ", entries=", result.Entries, ", synced=", result.Synced)
+
+	case "verify":
+
+		if param != "" && param != "repair" {
+			log.Fatal("Incorrect param for action ", action, ": ", param)
+		}
+
+		report, err := s.VerifyChain(chain, param == "repair")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, problem := range report.Problems {
+			log.Warn(problem.Type, ": eblock=", problem.EBlock, ", entryHash=", problem.EntryHash, ", repaired=", problem.Repaired, " — ", problem.Description)
+		}
+
+		log.Info("Chain ", report.ChainID, " verified: eblocks=", report.EBlocks, ", entries=", report.Entries, ", problems=", len(report.Problems))
+
+	default:
+
+		log.Fatal("Incorrect action: ", action)
+
+	}
+
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/DeFacto-Team/Factom-Open-API/config"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
+	"github.com/FactomProject/factom"
 	_ "github.com/lib/pq"
 	log "github.com/sirupsen/logrus"
 )
@@ -88,6 +90,8 @@ func main() {
 		fmt.Printf("user delete john — Delete user 'john'\n")
 		fmt.Printf("user rotate-key john — Rotate API access key for user 'john'\n")
 		fmt.Printf("user set-limit john 1000 — Set writes limit for user 'john' to 1000\n")
+		fmt.Printf("user set-es john — Set own Es address (prompted) for user 'john' to pay for user's writes\n")
+		fmt.Printf("user remove-es john — Remove own Es address of user 'john'\n")
 		fmt.Printf("user ls — Show all API users, their API keys, statuses & limits\n")
 
 	case "create":
@@ -171,6 +175,42 @@ func main() {
 
 		log.Info("Usage limit for user ", user.Name, " set to ", user.UsageLimit, " write(s)")
 
+	case "set-es":
+
+		// private key is prompted, so it's not kept in shell history & not logged
+		esAddress, err := wallet.PromptSecret("Es address: ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ec, err := factom.GetECAddress(esAddress)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		user.EsAddress, err = wallet.EncryptEsAddress(conf, esAddress)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		user.ECAddress = ec.PubString()
+
+		err = store.UpdateUser(user)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Info("EC address ", user.ECAddress, " set for user ", user.Name)
+
+	case "remove-es":
+
+		err = store.RemoveUserEsAddress(user)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Info("EC address removed for user ", user.Name)
+
 	case "ls":
 
 		users := store.GetUsers(&model.User{})
@@ -185,7 +225,7 @@ func main() {
 				} else {
 					status = "disabled"
 				}
-				log.Info("id=", u.ID, ", name=", u.Name, ", accessToken=", u.AccessToken, ", status=", status, ", usage=", u.Usage, ", usageLimit=", u.UsageLimit)
+				log.Info("id=", u.ID, ", name=", u.Name, ", accessToken=", u.AccessToken, ", status=", status, ", usage=", u.Usage, ", usageLimit=", u.UsageLimit, ", ecAddress=", u.ECAddress)
 			}
 		}
 
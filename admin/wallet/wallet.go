@@ -0,0 +1,154 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+
+	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+
+	var err error
+	var action, keystore string
+
+	var conf *config.Config
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	configFile := usr.HomeDir + "/.foa/config.yaml"
+
+	flag.StringVar(&configFile, "c", configFile, "config.yaml path")
+	flag.StringVar(&keystore, "k", "", "keystore path (wallet.keystore from config is used by default)")
+	flag.Parse()
+
+	args := flag.Args()
+
+	if len(args) == 0 {
+		log.Fatal("No params provided")
+	}
+
+	action = args[0]
+
+	// private keys & passphrases are never logged, only action
+	log.Info("action=", action)
+
+	if keystore == "" && action != "help" {
+		if conf, err = config.NewConfig(configFile); err != nil {
+			log.Fatal(err)
+		}
+		keystore = conf.Wallet.Keystore
+	}
+
+	if keystore == "" && action != "help" {
+		log.Fatal("Keystore path is not set in config or -k flag")
+	}
+
+	switch action {
+	case "help":
+
+		fmt.Printf("Wallet keystore tool for Factom Open API:\n")
+		fmt.Printf("wallet help — Show help\n")
+		fmt.Printf("wallet import — Encrypt Es address with passphrase & add it into keystore (keystore is created if it doesn't exist)\n")
+		fmt.Printf("wallet address — Show public EC addresses of keystore\n")
+		fmt.Printf("wallet passwd — Change passphrase of keystore\n")
+		fmt.Printf("Passphrase is read from %s environment variable or prompted\n", wallet.KeystorePassphraseEnv)
+
+	case "import":
+
+		esAddress, err := wallet.PromptSecret("Es address: ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		passphrase, err := readPassphrase("Keystore passphrase: ", !exists(keystore))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		address, err := wallet.ImportToKeystore(keystore, passphrase, esAddress)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Info("EC address ", address, " imported into keystore ", keystore)
+
+	case "address":
+
+		addresses, err := wallet.KeystoreAddresses(keystore)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, address := range addresses {
+			fmt.Println(address)
+		}
+
+	case "passwd":
+
+		oldPassphrase, err := wallet.ReadPassphrase("Current passphrase: ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// new passphrase is always prompted, environment variable holds the current one
+		newPassphrase, err := promptNewPassphrase("New passphrase: ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := wallet.ChangeKeystorePassphrase(keystore, oldPassphrase, newPassphrase); err != nil {
+			log.Fatal(err)
+		}
+
+		log.Info("Passphrase of keystore ", keystore, " changed")
+
+	default:
+
+		log.Fatal("Incorrect action: ", action)
+
+	}
+
+}
+
+// readPassphrase reads passphrase from environment variable or prompts it, new passphrase is prompted twice
+func readPassphrase(prompt string, isNew bool) (string, error) {
+
+	if _, ok := os.LookupEnv(wallet.KeystorePassphraseEnv); ok || !isNew {
+		return wallet.ReadPassphrase(prompt)
+	}
+
+	return promptNewPassphrase(prompt)
+
+}
+
+func promptNewPassphrase(prompt string) (string, error) {
+
+	passphrase, err := wallet.PromptSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	repeat, err := wallet.PromptSecret("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase != repeat {
+		return "", fmt.Errorf("Passphrases do not match")
+	}
+
+	return passphrase, nil
+
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"bytes"
+	"crypto/subtle"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -63,6 +65,8 @@ const (
 	DefaultPaginationLimit = 30
 	DefaultSort            = "desc"
 	AlternativeSort        = "asc"
+	ExportFlushInterval    = 100
+	MaxEstimateItems       = 100
 )
 
 func NewAPI(conf *config.Config, s service.Service) *API {
@@ -100,6 +104,22 @@ func NewAPI(conf *config.Config, s service.Service) *API {
 
 	api.apiInfo.MW = append(api.apiInfo.MW, "KeyAuth")
 
+	// Admin endpoints are available only if admin access token is set in config
+	var adminGroup *echo.Group
+
+	if conf.Admin.AccessToken != "" {
+		adminGroup = api.HTTP.Group("/v1/admin")
+		adminGroup.Use(middleware.KeyAuth(func(key string, c echo.Context) (bool, error) {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(conf.Admin.AccessToken)) == 1 {
+				return true, nil
+			}
+			err := fmt.Errorf("Invalid admin auth key")
+			log.Error(err)
+			return false, err
+		}))
+		api.apiInfo.MW = append(api.apiInfo.MW, "AdminKeyAuth")
+	}
+
 	// Status
 	api.HTTP.GET("/v1", api.index)
 
@@ -112,16 +132,23 @@ func NewAPI(conf *config.Config, s service.Service) *API {
 	authGroup.POST("/chains", api.createChain)
 	authGroup.GET("/chains", api.getChains)
 	authGroup.GET("/chains/:chainid", api.getChain)
+	authGroup.DELETE("/chains/:chainid", api.untrackChain)
 	authGroup.POST("/chains/search", api.searchChains)
 
 	// Chains entries
 	authGroup.GET("/chains/:chainid/entries", api.getChainEntries)
 	authGroup.POST("/chains/:chainid/entries/search", api.searchChainEntries)
 	authGroup.GET("/chains/:chainid/entries/:item", api.getChainFirstOrLastEntry)
+	authGroup.GET("/chains/:chainid/export", api.exportChain)
 
 	// Entries
 	authGroup.POST("/entries", api.createEntry)
 	authGroup.GET("/entries/:entryhash", api.getEntry)
+	authGroup.GET("/entries/:entryhash/content", api.getEntryContent)
+	authGroup.GET("/entries/:entryhash/receipt", api.getEntryReceipt)
+
+	// Estimate
+	authGroup.POST("/estimate", api.estimate)
 
 	// User
 	authGroup.GET("/user", api.getUser)
@@ -129,6 +156,21 @@ func NewAPI(conf *config.Config, s service.Service) *API {
 	// Direct factomd call
 	authGroup.POST("/factomd/:method", api.factomd)
 
+	// Admin
+	if adminGroup != nil {
+		adminGroup.GET("/chains/syncing", api.getSyncingChains)
+		adminGroup.GET("/updates", api.getUpdatesStatus)
+		adminGroup.GET("/wallet", api.getWallet)
+		adminGroup.GET("/wallet/purchases", api.getECPurchases)
+		adminGroup.POST("/chains/:chainid/import", api.importChain)
+		adminGroup.POST("/chains/:chainid/sync/bump", api.bumpChainSync)
+		adminGroup.POST("/chains/:chainid/sync/pause", api.pauseChainSync)
+		adminGroup.POST("/chains/:chainid/sync/resume", api.resumeChainSync)
+		adminGroup.POST("/chains/:chainid/sync/cancel", api.cancelChainSync)
+		adminGroup.POST("/chains/:chainid/sync/resync", api.resyncChain)
+		adminGroup.POST("/chains/:chainid/verify", api.verifyChain)
+	}
+
 	return api
 }
 
@@ -144,14 +186,27 @@ func (api *API) GetAPIInfo() APIInfo {
 
 // getUser godoc
 // @Summary User info
-// @Description Get API user info
+// @Description Get API user info, including balance of user's own EC address (if set)
 // @Accept x-www-form-urlencoded
 // @Accept json
 // @Produce json
 // @Success 200 {object} api.SuccessResponse
 // @Router /user [get]
 func (api *API) getUser(c echo.Context) error {
-	return c.JSON(http.StatusOK, &api.user)
+
+	user := *api.user
+
+	if user.ECAddress != "" {
+		balance, err := api.service.GetUserECBalance(&user)
+		if err != nil {
+			log.Error(err)
+		} else {
+			user.ECBalance = &balance
+		}
+	}
+
+	return c.JSON(http.StatusOK, &user)
+
 }
 
 // index godoc
@@ -168,14 +223,7 @@ func (api *API) index(c echo.Context) error {
 
 func (api *API) checkUserLimit(action string, c echo.Context) error {
 
-	var usageCost int
-
-	switch action {
-	case model.QueueActionChain:
-		usageCost = 2
-	case model.QueueActionEntry:
-		usageCost = 1
-	}
+	usageCost := model.QueueActionWrites(action)
 
 	if api.user.UsageLimit != 0 && api.user.UsageLimit-api.user.Usage < usageCost {
 		return fmt.Errorf("Writes limit (%d writes) is exceeded for API user '%s'", api.user.UsageLimit, api.user.Name)
@@ -202,6 +250,21 @@ func (api *API) AcceptedResponse(res interface{}, mes string, c echo.Context) er
 	return c.JSON(http.StatusAccepted, resp)
 }
 
+// Accepted API response for chain, that is syncing, with chain & its sync progress as result
+func (api *API) chainSyncingResponse(chainID string, encoding string, mes string, c echo.Context) error {
+
+	var res interface{}
+
+	if chain := api.service.GetChainSyncStatus(&model.Chain{ChainID: chainID}); chain != nil {
+		if converted, err := chain.ConvertEncoding(model.EncodingBase64, encoding); err == nil {
+			res = converted.ConvertToChainWithLinks()
+		}
+	}
+
+	return api.AcceptedResponse(res, mes, c)
+
+}
+
 // Success API response with pagination params
 func (api *API) SuccessResponsePagination(res interface{}, total int, c echo.Context) error {
 
@@ -218,6 +281,14 @@ func (api *API) SuccessResponsePagination(res interface{}, total int, c echo.Con
 	return c.JSON(http.StatusOK, resp)
 }
 
+// encodingErrorResponse responds with ValidationError, if data can't be represented in requested encoding
+func (api *API) encodingErrorResponse(err error, c echo.Context) error {
+	if err == model.ErrInvalidUTF8 {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+	return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+}
+
 // Custom API response in case of error
 func (api *API) ErrorResponse(err *errors.Error, c echo.Context) error {
 	resp := &ErrorResponse{
@@ -277,6 +348,62 @@ func (api *API) GetPaginationParams(c echo.Context) (int, int, string, error) {
 
 }
 
+// Helper function: check if encoding param is supported;
+// returns encoding of Content & ExtIDs in request and response;
+// model.DefaultEncoding is used if param was not provided
+func (api *API) GetEncodingParam(c echo.Context) (string, error) {
+
+	encoding := model.DefaultEncoding
+
+	if c.QueryParam("encoding") != "" {
+		encoding = c.QueryParam("encoding")
+		if !model.IsValidEncoding(encoding) {
+			err := fmt.Errorf("'encoding' expected to be one of: %s, %s, %s, '%s' received", model.EncodingBase64, model.EncodingUTF8, model.EncodingHex, encoding)
+			log.Error(err)
+			return "", err
+		}
+	}
+
+	return encoding, nil
+
+}
+
+// Get track param from request, true by default
+func (api *API) GetTrackParam(c echo.Context) (bool, error) {
+
+	if c.QueryParam("track") == "" {
+		return true, nil
+	}
+
+	track, err := strconv.ParseBool(c.QueryParam("track"))
+	if err != nil {
+		err = fmt.Errorf("'track' expected to be boolean")
+		log.Error(err)
+		return false, err
+	}
+
+	return track, nil
+
+}
+
+// Get dryRun param from request, false by default
+func (api *API) GetDryRunParam(c echo.Context) (bool, error) {
+
+	if c.QueryParam("dryRun") == "" {
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(c.QueryParam("dryRun"))
+	if err != nil {
+		err = fmt.Errorf("'dryRun' expected to be boolean")
+		log.Error(err)
+		return false, err
+	}
+
+	return dryRun, nil
+
+}
+
 // API functions
 
 // createChain godoc
@@ -287,17 +414,14 @@ func (api *API) GetPaginationParams(c echo.Context) (int, int, string, error) {
 // @Produce json
 // @Param extIds formData array true "One or many external ids identifying new chain.<br />**Should be provided as array of base64 strings.**"
 // @Param content formData string false "The content of the first entry of the chain.<br />**Should be provided as base64 string.**"
+// @Param encoding query string false "Encoding of extIds & content.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Param dryRun query boolean false "Validate chain & return its ChainID, EntryHash of the first entry & EC cost without creating it. Writes limit of user is not checked.<br />*Default: false*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /chains [post]
 func (api *API) createChain(c echo.Context) error {
 
-	// check user limits
-	if err := api.checkUserLimit(model.QueueActionChain, c); err != nil {
-		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
-	}
-
 	// Open API Chain struct
 	req := &model.Chain{}
 
@@ -314,21 +438,58 @@ func (api *API) createChain(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	log.Debug("Validating input data")
 
+	// convert ExtIDs, Content into base64
+	req, err = req.ConvertEncoding(encoding, model.EncodingBase64)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// validate ExtIDs, Content
 	if err := api.validate.StructExcept(req, "ChainID"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	dryRun, err := api.GetDryRunParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// nothing is written into DB & queue, usage of user is not changed
+	if dryRun {
+		estimate, err := api.service.ValidateChain(req)
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		}
+		return api.SuccessResponse(estimate, c)
+	}
+
+	// check user limits, dry run uses no writes, so it is not limited
+	if err := api.checkUserLimit(model.QueueActionChain, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
+	}
+
 	chain, err := api.service.CreateChain(req, api.user)
 
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	firstEntryHash := chain.Base64Decode().FirstEntryHash()
+
+	chain, err = chain.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	resp := &model.ChainWithLinks{Chain: chain}
-	resp.Links = append(resp.Links, model.Link{Rel: "firstEntry", Href: "/entries/" + chain.Base64Decode().FirstEntryHash()})
+	resp.Links = append(resp.Links, model.Link{Rel: "firstEntry", Href: "/entries/" + firstEntryHash})
 
 	return api.SuccessResponse(resp, c)
 }
@@ -343,6 +504,7 @@ func (api *API) createChain(c echo.Context) error {
 // @Param limit query integer false "The number of items you would like back in each page.<br />*Default: 30*"
 // @Param status query string false "Filter results by chain's status.<br />One of: **queue**, **processing**, **completed**<br />*By default filtering disabled.*"
 // @Param sort query string false "Sorting order.<br />One of: **asc** or **desc**<br />*Default: desc*"
+// @Param encoding query string false "Encoding of extIds & content in response.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
 // @Success 200 {object} api.SuccessResponsePagination
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -365,10 +527,20 @@ func (api *API) getChains(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
 	}
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	resp, total := api.service.GetUserChains(chain, api.user, start, limit, sort)
 
 	chains := &model.Chains{Items: resp}
 
+	err = chains.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
 
 }
@@ -384,6 +556,7 @@ func (api *API) getChains(c echo.Context) error {
 // @Param limit query integer false "The number of items you would like back in each page.<br />*Default: 30*"
 // @Param status query string false "Filter results by chain's status.<br />One of: **queue**, **processing**, **completed**<br />*By default filtering disabled.*"
 // @Param sort query string false "Sorting order.<br />One of: **asc** or **desc**<br />*Default: desc*"
+// @Param encoding query string false "Encoding of extIds & content.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -398,9 +571,20 @@ func (api *API) searchChains(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	log.Debug("Validating input data")
 	req.Status = c.QueryParam("status")
 
+	// convert ExtIDs into base64
+	req, err = req.ConvertEncoding(encoding, model.EncodingBase64)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// validate ExtIDs
 	if err := api.validate.StructPartial(req, "ExtIDs", "Status"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
@@ -415,6 +599,11 @@ func (api *API) searchChains(c echo.Context) error {
 
 	chains := &model.Chains{Items: resp}
 
+	err = chains.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	return api.SuccessResponsePagination(chains.ConvertToChainsWithLinks(), total, c)
 
 }
@@ -426,6 +615,8 @@ func (api *API) searchChains(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param chainId path string true "Chain ID of the Factom chain."
+// @Param encoding query string false "Encoding of extIds & content in response.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Param track query boolean false "Store chain into local DB, bind it to user & sync its history.<br />Set **track=false** for read-through lookup.<br />*Default: true*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -441,15 +632,64 @@ func (api *API) getChain(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	resp, err := api.service.GetChain(req, api.user)
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	track, err := api.GetTrackParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	var resp *model.Chain
+	if track {
+		resp, err = api.service.GetChain(req, api.user)
+	} else {
+		resp, err = api.service.LookupChain(req)
+	}
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	resp, err = resp.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
 
 }
 
+// untrackChain godoc
+// @Summary Untrack chain
+// @Description Removes chain from user's chains. Chains, that are not tracked by any user, may be deleted from local DB if garbage collection is enabled.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 204 "Chain is untracked"
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /chains/{chainId} [delete]
+func (api *API) untrackChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	err := api.service.UntrackChain(req, api.user)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+
+}
+
 // createEntry godoc
 // @Summary Create an entry
 // @Description Creates entry on the Factom blockchain
@@ -459,17 +699,14 @@ func (api *API) getChain(c echo.Context) error {
 // @Param chainId formData string true "Chain ID of the Factom chain, where to add new entry."
 // @Param extIds formData array false "One or many external ids identifying new chain.<br />**Should be provided as array of base64 strings.**"
 // @Param content formData string false "The content of the new entry of the chain.<br />**Should be provided as base64 string.**"
+// @Param encoding query string false "Encoding of extIds & content.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Param dryRun query boolean false "Validate entry & return its EntryHash & EC cost without creating it. Writes limit of user is not checked.<br />*Default: false*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
 // @Router /entries [post]
 func (api *API) createEntry(c echo.Context) error {
 
-	// check user limits
-	if err := api.checkUserLimit(model.QueueActionEntry, c); err != nil {
-		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
-	}
-
 	// Open API Entry struct
 	req := &model.Entry{}
 
@@ -478,22 +715,130 @@ func (api *API) createEntry(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
 	}
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	log.Debug("Validating input data")
 
+	// convert ExtIDs, Content into base64
+	req, err = req.ConvertEncoding(encoding, model.EncodingBase64)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// validate ChainID, ExtID (if exists), Content (if exists)
 	if err := api.validate.StructExcept(req, "EntryHash"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	dryRun, err := api.GetDryRunParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// nothing is written into DB & queue, usage of user is not changed
+	if dryRun {
+		estimate, err := api.service.ValidateEntry(req)
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+		}
+		return api.SuccessResponse(estimate, c)
+	}
+
+	// check user limits, dry run uses no writes, so it is not limited
+	if err := api.checkUserLimit(model.QueueActionEntry, c); err != nil {
+		return api.ErrorResponse(errors.New(errors.LimitationError, err), c)
+	}
+
 	// Create entry
 	resp, err := api.service.CreateEntry(req, api.user)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	resp, err = resp.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	return api.SuccessResponse(resp, c)
 }
 
+// estimate godoc
+// @Summary Estimate cost of writes
+// @Description Returns size, EC cost, ChainID & EntryHash of chain or entry without writing it.<br />Accepts the same body as POST /chains (if no chainId) or POST /entries, or JSON array of them to estimate a batch.
+// @Accept json
+// @Produce json
+// @Param chainId formData string false "Chain ID of the Factom chain, where to add new entry.<br />If not provided, the first entry of the new chain is estimated."
+// @Param extIds formData array false "One or many external ids.<br />**Should be provided as array of base64 strings.**"
+// @Param content formData string false "The content of the entry.<br />**Should be provided as base64 string.**"
+// @Param encoding query string false "Encoding of extIds & content.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Router /estimate [post]
+func (api *API) estimate(c echo.Context) error {
+
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	// single chain or entry, or batch of them
+	var reqs []*model.Entry
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		err = json.Unmarshal(body, &reqs)
+	} else {
+		req := &model.Entry{}
+		err = json.Unmarshal(body, req)
+		reqs = append(reqs, req)
+	}
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.BindDataError, err), c)
+	}
+
+	if len(reqs) == 0 || len(reqs) > MaxEstimateItems {
+		return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("From 1 to %d items can be estimated at once", MaxEstimateItems)), c)
+	}
+
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	log.Debug("Validating input data")
+
+	for i, req := range reqs {
+
+		if req == nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, fmt.Errorf("Item %d is empty", i)), c)
+		}
+
+		// convert ExtIDs, Content into base64
+		req, err = req.ConvertEncoding(encoding, model.EncodingBase64)
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		}
+		reqs[i] = req
+
+		// validate the same way as createChain() & createEntry()
+		if req.ChainID == "" {
+			err = api.validate.StructExcept(&model.Chain{ExtIDs: req.ExtIDs, Content: req.Content}, "ChainID")
+		} else {
+			err = api.validate.StructExcept(req, "EntryHash")
+		}
+		if err != nil {
+			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		}
+
+	}
+
+	return api.SuccessResponse(api.service.EstimateWrites(reqs, api.user), c)
+
+}
+
 // getEntry godoc
 // @Summary Get entry
 // @Description Returns Factom entry by EntryHash
@@ -501,6 +846,8 @@ func (api *API) createEntry(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param entryHash path string true "EntryHash of the Factom entry."
+// @Param encoding query string false "Encoding of extIds & content in response.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Param track query boolean false "Store chain into local DB, bind it to user & sync its history.<br />Set **track=false** for read-through lookup.<br />*Default: true*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -516,66 +863,178 @@ func (api *API) getEntry(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	resp, err := api.service.GetEntry(req, api.user)
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	track, err := api.GetTrackParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	var resp *model.Entry
+	if track {
+		resp, err = api.service.GetEntry(req, api.user)
+	} else {
+		resp, err = api.service.LookupEntry(req)
+	}
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
+	resp, err = resp.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
 	return api.SuccessResponse(resp, c)
 
 }
 
-// getChainEntries godoc
-// @Summary Get chain entries
-// @Description Returns entries of Factom chain
+// getEntryContent godoc
+// @Summary Get entry content
+// @Description Returns raw content of Factom entry with detected Content-Type
 // @Accept x-www-form-urlencoded
 // @Accept json
-// @Produce json
-// @Param chainId path string true "Chain ID of the Factom chain."
-// @Param start query integer false "Select item you would like to start.<br />E.g. if you've already seen 30 items and want to see next 30, then you will provide **start=30**.<br />*Default: 0*"
-// @Param limit query integer false "The number of items you would like back in each page.<br />*Default: 30*"
-// @Param status query string false "Filter results by chain's status.<br />One of: **queue**, **processing**, **completed**<br />*By default filtering disabled.*"
-// @Param sort query string false "Sorting order.<br />One of: **asc** or **desc**<br />*Default: desc*"
-// @Success 200 {object} api.SuccessResponsePagination
+// @Produce octet-stream
+// @Param entryHash path string true "EntryHash of the Factom entry."
+// @Param track query boolean false "Store chain into local DB, bind it to user & sync its history.<br />Set **track=false** for read-through lookup.<br />*Default: true*"
+// @Success 200 {string} string
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
-// @Router /chains/{chainId}/entries [get]
-func (api *API) getChainEntries(c echo.Context) error {
-
-	var force bool
+// @Router /entries/{entryHash}/content [get]
+func (api *API) getEntryContent(c echo.Context) error {
 
-	req := &model.Entry{ChainID: c.Param("chainid")}
-	req.Status = c.QueryParam("status")
+	req := &model.Entry{EntryHash: c.Param("entryhash")}
 
 	log.Debug("Validating input data")
 
-	// validate ChainID
-	if err := api.validate.StructPartial(req, "ChainID", "Status"); err != nil {
+	// validate EntryHash
+	if err := api.validate.StructPartial(req, "EntryHash"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	start, limit, sort, err := api.GetPaginationParams(c)
+	track, err := api.GetTrackParam(c)
 	if err != nil {
-		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
-	if c.QueryParam("force") == "true" {
-		force = true
+	var resp *model.Entry
+	if track {
+		resp, err = api.service.GetEntry(req, api.user)
+	} else {
+		resp, err = api.service.LookupEntry(req)
 	}
-
-	resp, total, err := api.service.GetChainEntries(req, api.user, start, limit, sort, force)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
-	if err == nil && resp == nil {
-		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' to request to get partial data.", c)
+
+	content, err := model.Decode(resp.Content, model.EncodingBase64)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
-	return api.SuccessResponsePagination(resp, total, c)
+	return c.Blob(http.StatusOK, http.DetectContentType(content), content)
 
 }
 
-// searchChainEntries godoc
+// getEntryReceipt godoc
+// @Summary Get entry receipt
+// @Description Returns cryptographic proof of Factom entry: Merkle path from entry to entry block and directory block, and Bitcoin anchor (when available)
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Produce json
+// @Param entryHash path string true "EntryHash of the Factom entry."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /entries/{entryHash}/receipt [get]
+func (api *API) getEntryReceipt(c echo.Context) error {
+
+	req := &model.Entry{EntryHash: c.Param("entryhash")}
+
+	log.Debug("Validating input data")
+
+	// validate EntryHash
+	if err := api.validate.StructPartial(req, "EntryHash"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.GetEntryReceipt(req, api.user)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+	if err == nil && resp == nil {
+		return api.AcceptedResponse(resp, "Entry is not included into directory block yet. Please wait for a while and try again.", c)
+	}
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// getChainEntries godoc
+// @Summary Get chain entries
+// @Description Returns entries of Factom chain
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Param start query integer false "Select item you would like to start.<br />E.g. if you've already seen 30 items and want to see next 30, then you will provide **start=30**.<br />*Default: 0*"
+// @Param limit query integer false "The number of items you would like back in each page.<br />*Default: 30*"
+// @Param status query string false "Filter results by chain's status.<br />One of: **queue**, **processing**, **completed**<br />*By default filtering disabled.*"
+// @Param sort query string false "Sorting order.<br />One of: **asc** or **desc**<br />*Default: desc*"
+// @Param encoding query string false "Encoding of extIds & content in response.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
+// @Success 200 {object} api.SuccessResponsePagination
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /chains/{chainId}/entries [get]
+func (api *API) getChainEntries(c echo.Context) error {
+
+	var force bool
+
+	req := &model.Entry{ChainID: c.Param("chainid")}
+	req.Status = c.QueryParam("status")
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID", "Status"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	start, limit, sort, err := api.GetPaginationParams(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.PaginationError, err), c)
+	}
+
+	if c.QueryParam("force") == "true" {
+		force = true
+	}
+
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, total, err := api.service.GetChainEntries(req, api.user, start, limit, sort, force)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+	if err == nil && resp == nil {
+		return api.chainSyncingResponse(req.ChainID, encoding, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' to request to get partial data.", c)
+	}
+
+	resp, err = convertEntriesEncoding(resp, model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
+	return api.SuccessResponsePagination(resp, total, c)
+
+}
+
+// searchChainEntries godoc
 // @Summary Search entries of chain
 // @Description Search entries into Factom chain by external id(s)
 // @Accept x-www-form-urlencoded
@@ -587,6 +1046,7 @@ func (api *API) getChainEntries(c echo.Context) error {
 // @Param limit query integer false "The number of items you would like back in each page.<br />*Default: 30*"
 // @Param status query string false "Filter results by chain's status.<br />One of: **queue**, **processing**, **completed**<br />*By default filtering disabled.*"
 // @Param sort query string false "Sorting order.<br />One of: **asc** or **desc**<br />*Default: desc*"
+// @Param encoding query string false "Encoding of extIds & content.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
 // @Success 200 {object} api.SuccessResponsePagination
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -611,8 +1071,19 @@ func (api *API) searchChainEntries(c echo.Context) error {
 	req.ChainID = c.Param("chainid")
 	req.Status = c.QueryParam("status")
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	log.Debug("Validating input data")
 
+	// convert ExtIDs into base64
+	req, err = req.ConvertEncoding(encoding, model.EncodingBase64)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	// validate ChainID, ExtID
 	if err := api.validate.StructPartial(req, "ChainID", "ExtIDs", "Status"); err != nil {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
@@ -632,7 +1103,12 @@ func (api *API) searchChainEntries(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 	if err == nil && resp == nil {
-		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' to request to get partial data.", c)
+		return api.chainSyncingResponse(req.ChainID, encoding, "Chain is syncing. Please wait for a while and try again. Or add 'force=true' to request to get partial data.", c)
+	}
+
+	resp, err = convertEntriesEncoding(resp, model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
 	}
 
 	return api.SuccessResponsePagination(resp, total, c)
@@ -646,6 +1122,7 @@ func (api *API) searchChainEntries(c echo.Context) error {
 // @Accept json
 // @Produce json
 // @Param chainId path string true "Chain ID of the Factom chain."
+// @Param encoding query string false "Encoding of extIds & content in response.<br />One of: **base64**, **utf8**, **hex**<br />*Default: base64*"
 // @Success 200 {object} api.SuccessResponse
 // @Failure 400 {object} api.ErrorResponse
 // @Failure 500 {object} api.ErrorResponse
@@ -674,12 +1151,411 @@ func (api *API) getChainFirstOrLastEntry(c echo.Context) error {
 		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
 	}
 
+	encoding, err := api.GetEncodingParam(c)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
 	resp, err := api.service.GetChainFirstOrLastEntry(req, sort, api.user)
 	if err != nil {
 		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 	if err == nil && resp == nil {
-		return api.AcceptedResponse(resp, "Chain is syncing. Please wait for a while and try again.", c)
+		return api.chainSyncingResponse(req.ChainID, encoding, "Chain is syncing. Please wait for a while and try again.", c)
+	}
+
+	resp, err = resp.ConvertEncoding(model.EncodingBase64, encoding)
+	if err != nil {
+		return api.encodingErrorResponse(err, c)
+	}
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// exportChain godoc
+// @Summary Export chain
+// @Description Streams all locally stored entries of Factom chain in chain order.<br />If chain is not fully synced yet, response contains **Warning** header and partial data.
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Param format query string false "Export format.<br />One of: **ndjson**, **csv**<br />*Default: ndjson*"
+// @Success 200 {string} string
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /chains/{chainId}/export [get]
+func (api *API) exportChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	format := model.ExportFormatNDJSON
+	if c.QueryParam("format") != "" {
+		format = c.QueryParam("format")
+	}
+
+	var contentType string
+
+	switch format {
+	case model.ExportFormatNDJSON:
+		contentType = "application/x-ndjson"
+	case model.ExportFormatCSV:
+		contentType = "text/csv"
+	default:
+		err := fmt.Errorf("'format' expected to be one of: %s, %s, '%s' received", model.ExportFormatNDJSON, model.ExportFormatCSV, format)
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	chain, err := api.service.GetChain(req, api.user)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	resp := c.Response()
+
+	if chain.Synced == nil || !*chain.Synced {
+		resp.Header().Set("Warning", `199 - "Chain is syncing, export contains partial data"`)
+	}
+	resp.Header().Set(echo.HeaderContentType, contentType)
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s.%s", chain.ChainID, format))
+	resp.WriteHeader(http.StatusOK)
+
+	var write func(*model.ExportEntry) error
+	flush := resp.Flush
+
+	switch format {
+	case model.ExportFormatNDJSON:
+		encoder := json.NewEncoder(resp)
+		write = func(entry *model.ExportEntry) error {
+			return encoder.Encode(entry)
+		}
+	case model.ExportFormatCSV:
+		writer := csv.NewWriter(resp)
+		write = func(entry *model.ExportEntry) error {
+			return writer.Write(entry.CSVRecord())
+		}
+		flush = func() {
+			writer.Flush()
+			resp.Flush()
+		}
+		writer.Write(model.ExportCSVHeader())
+	}
+
+	i := 0
+
+	err = api.service.ExportChainEntries(chain, func(entry *model.ExportEntry) error {
+		if err := write(entry); err != nil {
+			return err
+		}
+		i++
+		if i%ExportFlushInterval == 0 {
+			flush()
+		}
+		return nil
+	})
+
+	flush()
+
+	// response is already started, so error can be only logged
+	if err != nil {
+		log.Error(err)
+	}
+
+	return nil
+
+}
+
+// getSyncingChains godoc
+// @Summary Syncing chains
+// @Description Returns all chains, that are being synced by workers or waiting for them, with their sync progress
+// @Produce json
+// @Success 200 {object} api.SuccessResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/syncing [get]
+func (api *API) getSyncingChains(c echo.Context) error {
+
+	chains := &model.Chains{Items: api.service.GetSyncingChains()}
+
+	return api.SuccessResponse(chains.ConvertToChainsWithLinks(), c)
+
+}
+
+// getUpdatesStatus godoc
+// @Summary Updates parser status
+// @Description Returns the latest directory block processed by updates parser & its lag behind factomd's leader height
+// @Produce json
+// @Success 200 {object} api.SuccessResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/updates [get]
+func (api *API) getUpdatesStatus(c echo.Context) error {
+
+	status, err := api.service.GetUpdatesStatus()
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(status, c)
+
+}
+
+// getWallet godoc
+// @Summary Wallet
+// @Description Returns EC addresses of wallet with their cached balances & EC spent by API, EC needed for queued writes & runway (EC left after paying for them)
+// @Produce json
+// @Success 200 {object} api.SuccessResponse
+// @Router /admin/wallet [get]
+func (api *API) getWallet(c echo.Context) error {
+
+	return api.SuccessResponse(api.service.GetWalletStatus(), c)
+
+}
+
+// getECPurchases godoc
+// @Summary EC purchases
+// @Description Returns audit log of EC purchases made by wallet auto top-up
+// @Produce json
+// @Success 200 {object} api.SuccessResponse
+// @Router /admin/wallet/purchases [get]
+func (api *API) getECPurchases(c echo.Context) error {
+
+	return api.SuccessResponse(api.service.GetECPurchases(), c)
+
+}
+
+// bumpChainSync godoc
+// @Summary Bump chain sync
+// @Description Sets priority of chain in the history-sync pool. Chains with higher priority are synced first.
+// @Accept x-www-form-urlencoded
+// @Accept json
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Param priority formData integer false "Priority of chain.<br />*Default: 1*"
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/sync/bump [post]
+func (api *API) bumpChainSync(c echo.Context) error {
+
+	var err error
+	priority := 1
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err = api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// validate priority, if exists
+	if c.FormValue("priority") != "" {
+		priority, err = strconv.Atoi(c.FormValue("priority"))
+		if err != nil {
+			err = fmt.Errorf("'priority' expected to be integer")
+			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		}
+	}
+
+	resp, err := api.service.SetChainSyncPriority(req, priority)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// pauseChainSync godoc
+// @Summary Pause chain sync
+// @Description Pauses sync job of chain. If chain is being synced, sync stops after the current entry block.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/sync/pause [post]
+func (api *API) pauseChainSync(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.PauseChainSync(req)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// resumeChainSync godoc
+// @Summary Resume chain sync
+// @Description Puts paused, failed or cancelled sync job of chain back into the history-sync pool. Attempts of job are reset.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/sync/resume [post]
+func (api *API) resumeChainSync(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.ResumeChainSync(req)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// cancelChainSync godoc
+// @Summary Cancel chain sync
+// @Description Cancels sync job of chain. Already fetched data is kept, chain is not synced until resumed or resynced.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/sync/cancel [post]
+func (api *API) cancelChainSync(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.CancelChainSync(req)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// resyncChain godoc
+// @Summary Resync chain
+// @Description Deletes all entry blocks & fetched entries of chain from local DB and syncs chain from scratch.<br />Running sync job should be paused or cancelled first.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/sync/resync [post]
+func (api *API) resyncChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.ResyncChain(req)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp.ConvertToChainWithLinks(), c)
+
+}
+
+// importChain godoc
+// @Summary Import chain
+// @Description Bootstraps local DB for the chain from NDJSON archive, made by chain export.<br />Entry hashes and linkage of entry blocks are verified before import, entry blocks are checked against Factom.<br />Chain becomes synced only if archive reaches the first entry block.
+// @Accept application/x-ndjson
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/import [post]
+func (api *API) importChain(c echo.Context) error {
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err := api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	resp, err := api.service.ImportChain(req, c.Request().Body)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
+	}
+
+	return api.SuccessResponse(resp, c)
+
+}
+
+// verifyChain godoc
+// @Summary Verify chain
+// @Description Checks integrity of local chain data: linkage of entry blocks, hashes of entries and completeness of entry blocks.<br />Missing & corrupted data is re-fetched from Factom if repair=true.
+// @Produce json
+// @Param chainId path string true "Chain ID of the Factom chain."
+// @Param repair query boolean false "Re-fetch missing & corrupted data from Factom"
+// @Success 200 {object} api.SuccessResponse
+// @Failure 400 {object} api.ErrorResponse
+// @Failure 500 {object} api.ErrorResponse
+// @Router /admin/chains/{chainId}/verify [post]
+func (api *API) verifyChain(c echo.Context) error {
+
+	var err error
+	repair := false
+
+	req := &model.Chain{ChainID: c.Param("chainid")}
+
+	log.Debug("Validating input data")
+
+	// validate ChainID
+	if err = api.validate.StructPartial(req, "ChainID"); err != nil {
+		return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+	}
+
+	// validate repair, if exists
+	if c.QueryParam("repair") != "" {
+		repair, err = strconv.ParseBool(c.QueryParam("repair"))
+		if err != nil {
+			err = fmt.Errorf("'repair' expected to be boolean")
+			return api.ErrorResponse(errors.New(errors.ValidationError, err), c)
+		}
+	}
+
+	resp, err := api.service.VerifyChain(req, repair)
+	if err != nil {
+		return api.ErrorResponse(errors.New(errors.ServiceError, err), c)
 	}
 
 	return api.SuccessResponse(resp, c)
@@ -727,6 +1603,26 @@ func (api *API) factomd(c echo.Context) error {
 
 }
 
+func convertEntriesEncoding(entries []*model.Entry, from string, to string) ([]*model.Entry, error) {
+
+	if from == to {
+		return entries, nil
+	}
+
+	res := make([]*model.Entry, len(entries))
+
+	for i, entry := range entries {
+		converted, err := entry.ConvertEncoding(from, to)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = converted
+	}
+
+	return res, nil
+
+}
+
 func bodyToJSON(c echo.Context) (map[string]interface{}, error) {
 
 	s, err := ioutil.ReadAll(c.Request().Body)
@@ -26,7 +26,52 @@ type Config struct {
 		URL       string `required:"true" default:"https://api.factomd.net"`
 		User      string `default:""`
 		Password  string `default:""`
-		EsAddress string `required:"true" default:""`
+		EsAddress string `default:""`
+		BatchSize int    `default:"50"`
+	}
+	Wallet struct {
+		// used together with Factom.EsAddress by local signer
+		EsAddresses []string
+		// path to keystore file with Es addresses encrypted by passphrase, used by local signer
+		Keystore string `default:""`
+		// public EC addresses used by remote signers
+		ECAddresses []string
+		// local, walletd or http
+		Signer         string `default:"local"`
+		SignerURL      string `default:""`
+		SignerUser     string `default:""`
+		SignerPassword string `default:""`
+		SignerToken    string `default:""`
+		Strategy       string `default:"roundrobin"`
+		// public EC addresses dedicated to users (user ID → EC address), used by user strategy
+		UserAddresses map[int]string
+		MinBalance    int64 `default:"0"`
+		// key used to encrypt Es addresses of users into DB
+		EncryptionKey string `default:""`
+		// pay for writes of users with Es address from server addresses, if user's address is out of EC
+		UserFallback bool `default:"false"`
+		// balances of server EC addresses are refreshed from factomd every N seconds (0 – balance monitor disabled)
+		BalanceRefresh int `default:"60"`
+		// alert if balance of server EC address drops below this threshold (0 – disabled)
+		AlertBalance int64 `default:"0"`
+		// alert if EC left after paying for queued writes drops below this threshold (0 – disabled)
+		AlertRunway int64 `default:"0"`
+		// alerts are POSTed to this URL, if set, and logged anyway
+		AlertWebhook string `default:""`
+		// Fs address used to buy EC for server EC addresses with balance below threshold (0 – auto top-up disabled)
+		TopUpFsAddress string `default:""`
+		TopUpThreshold int64  `default:"0"`
+		// EC address is topped up to this balance
+		TopUpTarget int64 `default:"0"`
+		// max EC bought during the last 24 hours (0 – unlimited)
+		TopUpDailyLimit int64 `default:"0"`
+	}
+	Admin struct {
+		AccessToken string `default:""`
+	}
+	GC struct {
+		Enabled     bool `default:"false"`
+		GracePeriod int  `default:"168"`
 	}
 }
 
@@ -58,6 +103,32 @@ func NewConfig(configFile string) (*Config, error) {
 	flag.StringVar(&config.Factom.User, "factomduser", config.Factom.User, "factomd user")
 	flag.StringVar(&config.Factom.Password, "factomdpass", config.Factom.Password, "factomd password")
 	flag.StringVar(&config.Factom.EsAddress, "esaddress", config.Factom.EsAddress, "Es address")
+	flag.IntVar(&config.Factom.BatchSize, "factomdbatch", config.Factom.BatchSize, "Max requests in JSON-RPC batch to factomd while syncing history (1 for single requests)")
+
+	flag.StringVar(&config.Wallet.Strategy, "walletstrategy", config.Wallet.Strategy, "EC address selection strategy (roundrobin, balance, user)")
+	flag.Int64Var(&config.Wallet.MinBalance, "walletminbalance", config.Wallet.MinBalance, "EC addresses with balance below this threshold are skipped")
+
+	flag.StringVar(&config.Wallet.Keystore, "walletkeystore", config.Wallet.Keystore, "Path to keystore file with encrypted Es addresses")
+
+	flag.StringVar(&config.Wallet.Signer, "walletsigner", config.Wallet.Signer, "Signer of commits (local, walletd, http)")
+	flag.StringVar(&config.Wallet.SignerURL, "walletsignerurl", config.Wallet.SignerURL, "URL of factom-walletd or HTTP signing service")
+
+	flag.StringVar(&config.Wallet.EncryptionKey, "walletkey", config.Wallet.EncryptionKey, "Key used to encrypt Es addresses of users")
+	flag.BoolVar(&config.Wallet.UserFallback, "walletuserfallback", config.Wallet.UserFallback, "Pay for writes of users from server EC addresses, if user's EC address balance is not enough")
+
+	flag.IntVar(&config.Wallet.BalanceRefresh, "walletbalancerefresh", config.Wallet.BalanceRefresh, "Seconds between refreshes of EC addresses balances")
+	flag.Int64Var(&config.Wallet.AlertBalance, "walletalertbalance", config.Wallet.AlertBalance, "Alert if EC address balance drops below this threshold (0 – disabled)")
+	flag.Int64Var(&config.Wallet.AlertRunway, "walletalertrunway", config.Wallet.AlertRunway, "Alert if EC left after paying for queued writes drops below this threshold (0 – disabled)")
+	flag.StringVar(&config.Wallet.AlertWebhook, "walletalertwebhook", config.Wallet.AlertWebhook, "URL to POST wallet alerts to")
+
+	flag.Int64Var(&config.Wallet.TopUpThreshold, "wallettopupthreshold", config.Wallet.TopUpThreshold, "Buy EC from Fs address when EC address balance drops below this threshold (0 – disabled)")
+	flag.Int64Var(&config.Wallet.TopUpTarget, "wallettopuptarget", config.Wallet.TopUpTarget, "Balance EC address is topped up to")
+	flag.Int64Var(&config.Wallet.TopUpDailyLimit, "wallettopupdailylimit", config.Wallet.TopUpDailyLimit, "Max EC bought during the last 24 hours (0 – unlimited)")
+
+	flag.StringVar(&config.Admin.AccessToken, "admintoken", config.Admin.AccessToken, "Admin endpoints access token (admin endpoints are disabled if empty)")
+
+	flag.BoolVar(&config.GC.Enabled, "gc", config.GC.Enabled, "Delete chains, that are not tracked by any user, from local DB")
+	flag.IntVar(&config.GC.GracePeriod, "gcgrace", config.GC.GracePeriod, "Hours after untracking by the last user before chain is deleted")
 
 	flag.Parse()
 
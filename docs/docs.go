@@ -1,6 +1,6 @@
 // GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
 // This file was generated by swaggo/swag at
-// 2019-04-21 23:46:47.135944 +0300 MSK m=+0.101234232
+// 2026-10-18 18:14:47.286331716 +0000 UTC m=+0.048625777
 
 package docs
 
@@ -52,6 +52,398 @@ var doc = `{
                 }
             }
         },
+        "/admin/chains/syncing": {
+            "get": {
+                "description": "Returns all chains, that are being synced by workers or waiting for them, with their sync progress",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Syncing chains",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/import": {
+            "post": {
+                "description": "Bootstraps local DB for the chain from NDJSON archive, made by chain export.\u003cbr /\u003eEntry hashes and linkage of entry blocks are verified before import, entry blocks are checked against Factom.\u003cbr /\u003eChain becomes synced only if archive reaches the first entry block.",
+                "consumes": [
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/sync/bump": {
+            "post": {
+                "description": "Sets priority of chain in the history-sync pool. Chains with higher priority are synced first.",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Bump chain sync",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Priority of chain.\u003cbr /\u003e*Default: 1*",
+                        "name": "priority",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/sync/cancel": {
+            "post": {
+                "description": "Cancels sync job of chain. Already fetched data is kept, chain is not synced until resumed or resynced.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Cancel chain sync",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/sync/pause": {
+            "post": {
+                "description": "Pauses sync job of chain. If chain is being synced, sync stops after the current entry block.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Pause chain sync",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/sync/resume": {
+            "post": {
+                "description": "Puts paused, failed or cancelled sync job of chain back into the history-sync pool. Attempts of job are reset.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Resume chain sync",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/sync/resync": {
+            "post": {
+                "description": "Deletes all entry blocks \u0026 fetched entries of chain from local DB and syncs chain from scratch.\u003cbr /\u003eRunning sync job should be paused or cancelled first.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Resync chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/chains/{chainId}/verify": {
+            "post": {
+                "description": "Checks integrity of local chain data: linkage of entry blocks, hashes of entries and completeness of entry blocks.\u003cbr /\u003eMissing \u0026 corrupted data is re-fetched from Factom if repair=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Verify chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Re-fetch missing \u0026 corrupted data from Factom",
+                        "name": "repair",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/updates": {
+            "get": {
+                "description": "Returns the latest directory block processed by updates parser \u0026 its lag behind factomd's leader height",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Updates parser status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallet": {
+            "get": {
+                "description": "Returns EC addresses of wallet with their cached balances \u0026 EC spent by API, EC needed for queued writes \u0026 runway (EC left after paying for them)",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Wallet",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wallet/purchases": {
+            "get": {
+                "description": "Returns audit log of EC purchases made by wallet auto top-up",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "EC purchases",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/chains": {
             "get": {
                 "description": "Returns all user's chains",
@@ -87,6 +479,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content in response.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -136,6 +534,18 @@ var doc = `{
                         "description": "The content of the first entry of the chain.\u003cbr /\u003e**Should be provided as base64 string.**",
                         "name": "content",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate chain \u0026 return its ChainID, EntryHash of the first entry \u0026 EC cost without creating it. Writes limit of user is not checked.\u003cbr /\u003e*Default: false*",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -205,6 +615,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -250,6 +666,18 @@ var doc = `{
                         "name": "chainId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content in response.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Store chain into local DB, bind it to user \u0026 sync its history.\u003cbr /\u003eSet **track=false** for read-through lookup.\u003cbr /\u003e*Default: true*",
+                        "name": "track",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -275,6 +703,41 @@ var doc = `{
                         }
                     }
                 }
+            },
+            "delete": {
+                "description": "Removes chain from user's chains. Chains, that are not tracked by any user, may be deleted from local DB if garbage collection is enabled.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Untrack chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Chain is untracked"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
             }
         },
         "/chains/{chainId}/entries": {
@@ -319,6 +782,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content in response.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -364,6 +833,12 @@ var doc = `{
                         "name": "chainId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content in response.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -440,6 +915,12 @@ var doc = `{
                         "description": "Sorting order.\u003cbr /\u003eOne of: **asc** or **desc**\u003cbr /\u003e*Default: desc*",
                         "name": "sort",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -467,6 +948,57 @@ var doc = `{
                 }
             }
         },
+        "/chains/{chainId}/export": {
+            "get": {
+                "description": "Streams all locally stored entries of Factom chain in chain order.\u003cbr /\u003eIf chain is not fully synced yet, response contains **Warning** header and partial data.",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv"
+                ],
+                "summary": "Export chain",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain.",
+                        "name": "chainId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format.\u003cbr /\u003eOne of: **ndjson**, **csv**\u003cbr /\u003e*Default: ndjson*",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/entries": {
             "post": {
                 "description": "Creates entry on the Factom blockchain",
@@ -497,6 +1029,18 @@ var doc = `{
                         "description": "The content of the new entry of the chain.\u003cbr /\u003e**Should be provided as base64 string.**",
                         "name": "content",
                         "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate entry \u0026 return its EntryHash \u0026 EC cost without creating it. Writes limit of user is not checked.\u003cbr /\u003e*Default: false*",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -535,6 +1079,113 @@ var doc = `{
                     "application/json"
                 ],
                 "summary": "Get entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EntryHash of the Factom entry.",
+                        "name": "entryHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content in response.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Store chain into local DB, bind it to user \u0026 sync its history.\u003cbr /\u003eSet **track=false** for read-through lookup.\u003cbr /\u003e*Default: true*",
+                        "name": "track",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/entries/{entryHash}/content": {
+            "get": {
+                "description": "Returns raw content of Factom entry with detected Content-Type",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "summary": "Get entry content",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "EntryHash of the Factom entry.",
+                        "name": "entryHash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Store chain into local DB, bind it to user \u0026 sync its history.\u003cbr /\u003eSet **track=false** for read-through lookup.\u003cbr /\u003e*Default: true*",
+                        "name": "track",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/entries/{entryHash}/receipt": {
+            "get": {
+                "description": "Returns cryptographic proof of Factom entry: Merkle path from entry to entry block and directory block, and Bitcoin anchor (when available)",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get entry receipt",
                 "parameters": [
                     {
                         "type": "string",
@@ -569,6 +1220,60 @@ var doc = `{
                 }
             }
         },
+        "/estimate": {
+            "post": {
+                "description": "Returns size, EC cost, ChainID \u0026 EntryHash of chain or entry without writing it.\u003cbr /\u003eAccepts the same body as POST /chains (if no chainId) or POST /entries, or JSON array of them to estimate a batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Estimate cost of writes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID of the Factom chain, where to add new entry.\u003cbr /\u003eIf not provided, the first entry of the new chain is estimated.",
+                        "name": "chainId",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "array",
+                        "description": "One or many external ids.\u003cbr /\u003e**Should be provided as array of base64 strings.**",
+                        "name": "extIds",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "The content of the entry.\u003cbr /\u003e**Should be provided as base64 string.**",
+                        "name": "content",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Encoding of extIds \u0026 content.\u003cbr /\u003eOne of: **base64**, **utf8**, **hex**\u003cbr /\u003e*Default: base64*",
+                        "name": "encoding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/factomd/{method}": {
             "post": {
                 "description": "Sends direct request to factomd API",
@@ -599,7 +1304,7 @@ var doc = `{
         },
         "/user": {
             "get": {
-                "description": "Get API user info",
+                "description": "Get API user info, including balance of user's own EC address (if set)",
                 "consumes": [
                     "application/x-www-form-urlencoded",
                     "application/json"
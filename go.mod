@@ -7,10 +7,10 @@ require (
 	github.com/FactomProject/basen v0.0.0-20150613233007-fe3947df716e // indirect
 	github.com/FactomProject/bolt v1.1.0 // indirect
 	github.com/FactomProject/btcd v0.3.5 // indirect
-	github.com/FactomProject/btcutil v0.0.0-20160826074221-43986820ccd5 // indirect
+	github.com/FactomProject/btcutil v0.0.0-20160826074221-43986820ccd5
 	github.com/FactomProject/btcutilecc v0.0.0-20130527213604-d3a63a5752ec // indirect
 	github.com/FactomProject/dynrsrc v0.3.1 // indirect
-	github.com/FactomProject/ed25519 v0.0.0-20150814230546-38002c4fe7b6 // indirect
+	github.com/FactomProject/ed25519 v0.0.0-20150814230546-38002c4fe7b6
 	github.com/FactomProject/factoid v0.3.4 // indirect
 	github.com/FactomProject/factom v0.0.0-20190321214556-fc1c06ae7272
 	github.com/FactomProject/factomd v6.2.2+incompatible // indirect
@@ -59,7 +59,7 @@ require (
 	github.com/swaggo/swag v1.5.0
 	github.com/valyala/fasttemplate v1.0.1 // indirect
 	github.com/ziutek/mymysql v1.5.4 // indirect
-	golang.org/x/crypto v0.0.0-20190418165655-df01cb2cc480 // indirect
+	golang.org/x/crypto v0.0.0-20190418165655-df01cb2cc480
 	golang.org/x/net v0.0.0-20190420063019-afa5a82059c6 // indirect
 	golang.org/x/sys v0.0.0-20190419153524-e8e3143a4f4a // indirect
 	golang.org/x/tools v0.0.0-20190420000508-685fecacd0a0 // indirect
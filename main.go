@@ -8,7 +8,6 @@ import (
 
 	"github.com/DeFacto-Team/Factom-Open-API/api"
 	"github.com/DeFacto-Team/Factom-Open-API/config"
-	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/pool"
 	"github.com/DeFacto-Team/Factom-Open-API/service"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
@@ -101,7 +100,7 @@ func main() {
 	}
 
 	// Create services
-	s := service.NewService(store, wallet)
+	s := service.NewService(conf, store, wallet)
 	log.Info("Services created successfully")
 
 	// Initialize pool for history fetching chains
@@ -111,7 +110,13 @@ func main() {
 	go fetchUnsyncedChains(s, collector)
 	go fetchChainUpdates(s)
 	go processQueue(s)
-	go clearQueue(s)
+	go trackQueueAcks(s)
+	if conf.Wallet.BalanceRefresh > 0 {
+		go monitorBalances(s, time.Duration(conf.Wallet.BalanceRefresh)*time.Second)
+	}
+	if conf.GC.Enabled {
+		go collectUntrackedChains(s, time.Duration(conf.GC.GracePeriod)*time.Hour)
+	}
 
 	// Start API
 	api := api.NewAPI(conf, s)
@@ -132,16 +137,41 @@ func fetchUnsyncedChains(s service.Service, collector pool.Collector) {
 
 	for {
 		log.Info("Fetching unsynced chains: iteration started")
-		t := false
-		chains := s.GetChains(&model.Chain{Synced: &t, WorkerID: -1, SentToPool: &t})
+		// already queued chains are upserted too, as their priority may change
+		chains := s.GetChainsToSync()
+		pending := make(map[string]bool)
 		for _, c := range chains {
-			s.SetChainSentToPool(c)
-			collector.Work <- pool.Work{ID: c.ChainID, Job: c, Service: s}
+			pending[c.ChainID] = true
+			work := pool.Work{ID: c.ChainID, Job: c, Service: s, Priority: c.SyncPriority, RequestedBy: c.SyncRequestedBy, Size: -1}
+			if !collector.Queue.Contains(c.ChainID) {
+				work.Size = s.EstimateChainSyncSize(c)
+				s.SetChainSentToPool(c)
+			}
+			collector.Work <- work
+		}
+		// sync jobs of queued chains may be paused or cancelled by admin, so such chains are removed from queue
+		for _, id := range collector.Queue.IDs() {
+			if !pending[id] {
+				collector.Work <- pool.Work{ID: id, Stopped: true}
+			}
 		}
 		time.Sleep(5 * time.Second)
 	}
 }
 
+func collectUntrackedChains(s service.Service, gracePeriod time.Duration) {
+
+	for {
+		log.Info("GC: iteration started")
+		n, err := s.CollectUntrackedChains(gracePeriod)
+		if err != nil {
+			log.Error(err)
+		}
+		log.Info("GC: ", n, " untracked chains deleted")
+		time.Sleep(1 * time.Hour)
+	}
+}
+
 func fetchChainUpdates(s service.Service) {
 
 	var currentMinute int    // current minute
@@ -151,6 +181,12 @@ func fetchChainUpdates(s service.Service) {
 	var sleepFor int         // sleep timer
 	var err error
 
+	// parsing continues from the latest dblock stored into DB
+	if latest := s.GetLatestDBlock(); latest != nil {
+		latestDBlock = int(latest.Height)
+		log.Info("Updates parser: Latest processed DBlock=", latestDBlock)
+	}
+
 	for {
 
 		log.Info("Updates parser: Iteration started")
@@ -163,7 +199,8 @@ func fetchChainUpdates(s service.Service) {
 		log.Info("Updates parser: currentMinute=", currentMinute, ", currentDBlock=", currentDBlock)
 
 		// if current dblock <= latest fetched dblock, then elections should occur and need to sleep 1 minute before next try
-		// on the first iteration latestDblock = 0, so this code won't run & new updates will be fetched when API started
+		// on the first start latestDblock = 0, so this code won't run & new updates will be fetched when API started
+		// after restart latestDBlock is the latest dblock stored into DB, so only missed dblocks are fetched
 		for currentDBlock <= latestDBlock {
 			log.Info("Updates parser: Sleeping for 1 minute / currentDBlock=", currentDBlock, ", latestDBlock=", latestDBlock)
 			time.Sleep(1 * time.Minute)
@@ -172,17 +209,20 @@ func fetchChainUpdates(s service.Service) {
 		}
 
 		// if we are here, then latestDBlock > currentDBlock (i.e. new dblock appeared)
-		// parsing chains updates
-		chains := s.GetChains(&model.Chain{Status: model.ChainCompleted})
-		for _, c := range chains {
-			err := s.ParseNewChainEntries(c)
-			if err != nil {
-				log.Error(err)
-			}
+		// parsing chains updates from new dblocks
+		err = s.ParseNewDBlocks(int64(currentDBlock))
+		if err != nil {
+			log.Error(err)
+		}
+
+		// updating latest parsed dblock, dblocks not processed because of error are retried on the next iteration
+		if latest := s.GetLatestDBlock(); latest != nil {
+			latestDBlock = int(latest.Height)
 		}
 
-		// updating latest parsed dblock
-		latestDBlock = currentDBlock
+		if status, err := s.GetUpdatesStatus(); err == nil {
+			log.Info("Updates parser: Lag behind leader height=", status.Lag)
+		}
 
 		// parsing may spend time, so check current minute
 		currentMinuteEnd, _, err = getMinuteAndHeight()
@@ -226,14 +266,27 @@ func processQueue(s service.Service) {
 	}
 }
 
-func clearQueue(s service.Service) {
+func trackQueueAcks(s service.Service) {
 	for {
-		log.Info("Clearing queue: iteration started")
-		queue := s.GetQueueToClear()
+		log.Debug("Ack tracking: iteration started")
+		queue := s.GetQueueToAck()
 		for _, q := range queue {
-			s.ClearQueue(q)
+			err := s.TrackQueueAck(q)
+			if err != nil {
+				log.Error(err)
+			}
+		}
+		time.Sleep(service.AckMinInterval)
+	}
+}
+
+func monitorBalances(s service.Service, interval time.Duration) {
+	for {
+		err := s.MonitorBalances()
+		if err != nil {
+			log.Error(err)
 		}
-		time.Sleep(60 * time.Second)
+		time.Sleep(interval)
 	}
 }
 
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE receipts(
+    entry_hash VARCHAR(64) UNIQUE NOT NULL,
+    merkle_branch JSONB,
+    entry_block_key_mr VARCHAR(64),
+    directory_block_key_mr VARCHAR(64),
+    bitcoin_transaction_hash VARCHAR(64),
+    bitcoin_block_hash VARCHAR(64),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    CONSTRAINT receipts_entry_hash_key PRIMARY KEY(entry_hash),
+    CONSTRAINT receipts_entry_hash_fkey FOREIGN KEY(entry_hash) REFERENCES entries(entry_hash)
+);
+
+-- +migrate Down
+DROP TABLE receipts;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE e_blocks ADD COLUMN entry_list _TEXT;
+
+-- +migrate Down
+ALTER TABLE e_blocks DROP COLUMN entry_list;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE chains ADD COLUMN sync_started_at TIMESTAMPTZ;
+ALTER TABLE chains ADD COLUMN sync_start_sequence INT NOT NULL DEFAULT -1;
+
+-- +migrate Down
+ALTER TABLE chains DROP COLUMN sync_start_sequence;
+ALTER TABLE chains DROP COLUMN sync_started_at;
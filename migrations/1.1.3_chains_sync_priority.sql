@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE chains ADD COLUMN sync_priority INT NOT NULL DEFAULT 0;
+ALTER TABLE chains ADD COLUMN sync_paused BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE chains ADD COLUMN sync_requested_by INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE chains DROP COLUMN sync_requested_by;
+ALTER TABLE chains DROP COLUMN sync_paused;
+ALTER TABLE chains DROP COLUMN sync_priority;
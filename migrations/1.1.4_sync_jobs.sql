@@ -0,0 +1,24 @@
+-- +migrate Up
+CREATE TABLE sync_jobs(
+    chain_id VARCHAR(64) UNIQUE NOT NULL,
+    state VARCHAR(32) NOT NULL,
+    attempts INT4 NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT sync_jobs_chain_id_key PRIMARY KEY(chain_id),
+    CONSTRAINT sync_jobs_chain_id_fkey FOREIGN KEY(chain_id) REFERENCES chains(chain_id)
+);
+
+INSERT INTO sync_jobs(chain_id, state, created_at, updated_at)
+SELECT chain_id, CASE WHEN synced THEN 'done' WHEN sync_paused THEN 'paused' ELSE 'pending' END, NOW(), NOW()
+FROM chains;
+
+ALTER TABLE chains DROP COLUMN sync_paused;
+
+-- +migrate Down
+ALTER TABLE chains ADD COLUMN sync_paused BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE chains SET sync_paused = TRUE WHERE chain_id IN (SELECT chain_id FROM sync_jobs WHERE state = 'paused');
+DROP TABLE sync_jobs;
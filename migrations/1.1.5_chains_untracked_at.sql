@@ -0,0 +1,6 @@
+-- +migrate Up
+ALTER TABLE chains ADD COLUMN untracked_at TIMESTAMPTZ;
+UPDATE chains SET untracked_at = NOW() WHERE NOT EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id);
+
+-- +migrate Down
+ALTER TABLE chains DROP COLUMN untracked_at;
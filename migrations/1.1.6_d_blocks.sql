@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE d_blocks(
+    height INT8 UNIQUE NOT NULL,
+    key_mr VARCHAR(64),
+    prev_key_mr VARCHAR(64),
+    timestamp INT8,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    CONSTRAINT d_blocks_height_key PRIMARY KEY(height)
+);
+
+-- +migrate Down
+DROP TABLE d_blocks;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE queue ADD COLUMN ack_status VARCHAR(32);
+ALTER TABLE queue ADD COLUMN next_ack_at TIMESTAMPTZ;
+ALTER TABLE queue ADD COLUMN ack_try_count INT4 NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE queue DROP COLUMN ack_status;
+ALTER TABLE queue DROP COLUMN next_ack_at;
+ALTER TABLE queue DROP COLUMN ack_try_count;
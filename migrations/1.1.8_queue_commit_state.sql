@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE queue ADD COLUMN commit_tx_id VARCHAR(64);
+ALTER TABLE queue ADD COLUMN state VARCHAR(32);
+
+-- +migrate Down
+ALTER TABLE queue DROP COLUMN commit_tx_id;
+ALTER TABLE queue DROP COLUMN state;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE queue ADD COLUMN ec_address VARCHAR(64);
+ALTER TABLE queue ADD COLUMN ec_cost INT8 NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE queue DROP COLUMN ec_address;
+ALTER TABLE queue DROP COLUMN ec_cost;
@@ -0,0 +1,7 @@
+-- +migrate Up
+ALTER TABLE users ADD COLUMN es_address TEXT;
+ALTER TABLE users ADD COLUMN ec_address VARCHAR(64);
+
+-- +migrate Down
+ALTER TABLE users DROP COLUMN es_address;
+ALTER TABLE users DROP COLUMN ec_address;
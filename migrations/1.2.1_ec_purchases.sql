@@ -0,0 +1,19 @@
+-- +migrate Up
+CREATE TABLE ec_purchases(
+    id SERIAL PRIMARY KEY,
+    fct_address VARCHAR(52),
+    ec_address VARCHAR(52),
+    ec_amount INT8 NOT NULL DEFAULT 0,
+    factoshis INT8 NOT NULL DEFAULT 0,
+    fee INT8 NOT NULL DEFAULT 0,
+    rate INT8 NOT NULL DEFAULT 0,
+    tx_id VARCHAR(64),
+    status VARCHAR(16),
+    error TEXT,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ
+);
+CREATE INDEX ec_purchases_created_at_idx ON ec_purchases(created_at);
+
+-- +migrate Down
+DROP TABLE ec_purchases;
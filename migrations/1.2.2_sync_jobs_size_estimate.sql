@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE sync_jobs ADD COLUMN size_estimate INT8 NOT NULL DEFAULT -1;
+
+-- +migrate Down
+ALTER TABLE sync_jobs DROP COLUMN size_estimate;
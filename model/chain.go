@@ -26,6 +26,12 @@ type Chain struct {
 	WorkerID           int            `json:"-" form:"-" query:"-" gorm:"not null;default:-1"`
 	SentToPool         *bool          `json:"-" form:"-" query:"-" gorm:"not null;default:false"`
 	FactomTime         *time.Time     `json:"createdAt"`
+	SyncStartedAt      *time.Time     `json:"-" form:"-" query:"-"`
+	SyncStartSequence  int64          `json:"-" form:"-" query:"-" gorm:"not null;default:-1"`
+	SyncPriority       int            `json:"-" form:"-" query:"-" gorm:"not null;default:0"`
+	SyncRequestedBy    int            `json:"-" form:"-" query:"-" gorm:"not null;default:0"`
+	UntrackedAt        *time.Time     `json:"-" form:"-" query:"-"`
+	SyncProgress       *SyncProgress  `json:"syncProgress,omitempty" form:"-" query:"-" sql:"-"`
 }
 
 type ChainWithLinks struct {
@@ -158,6 +164,7 @@ func (chain *Chain) ConvertToChainWithLinks() *ChainWithLinks {
 	resp.Links = append(resp.Links, Link{Rel: "entries", Href: "/chains/" + chain.ChainID + "/entries"})
 	resp.Links = append(resp.Links, Link{Rel: "firstEntry", Href: "/chains/" + chain.ChainID + "/entries/first"})
 	resp.Links = append(resp.Links, Link{Rel: "lastEntry", Href: "/chains/" + chain.ChainID + "/entries/last"})
+	resp.Links = append(resp.Links, Link{Rel: "export", Href: "/chains/" + chain.ChainID + "/export"})
 
 	return resp
 
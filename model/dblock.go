@@ -0,0 +1,83 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// DBlock reflects directory block processed by updates parser
+type DBlock struct {
+	CreatedAt time.Time `json:"-" form:"-" query:"-"`
+	UpdatedAt time.Time `json:"-" form:"-" query:"-"`
+	// model
+	Height         int64         `json:"height" gorm:"primary_key;unique;not null;auto_increment:false"`
+	KeyMR          string        `json:"keyMr"`
+	PrevKeyMR      string        `json:"prevKeyMr"`
+	Timestamp      int64         `json:"timestamp"`
+	EntryBlockList []DBlockEntry `json:"-" form:"-" query:"-" gorm:"-"`
+}
+
+// DBlockEntry is entry block of chain, included into directory block
+type DBlockEntry struct {
+	ChainID string `json:"chainid"`
+	KeyMR   string `json:"keymr"`
+}
+
+// NewDBlockFromJSON parses raw directory block, returned by factomd "dblock-by-height"
+func NewDBlockFromJSON(data []byte) (*DBlock, error) {
+
+	raw := struct {
+		Header struct {
+			DBHeight  int64  `json:"dbheight"`
+			PrevKeyMR string `json:"prevkeymr"`
+			Timestamp int64  `json:"timestamp"`
+		} `json:"header"`
+		KeyMR     string        `json:"keymr"`
+		DBEntries []DBlockEntry `json:"dbentries"`
+	}{}
+
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// timestamp of directory block header is in minutes
+	dblock := &DBlock{
+		Height:         raw.Header.DBHeight,
+		KeyMR:          raw.KeyMR,
+		PrevKeyMR:      raw.Header.PrevKeyMR,
+		Timestamp:      raw.Header.Timestamp * 60,
+		EntryBlockList: raw.DBEntries,
+	}
+
+	return dblock, nil
+
+}
+
+// UpdatesStatus reflects progress of updates parser
+type UpdatesStatus struct {
+	// latest directory block processed by updates parser, nil if none processed yet
+	LatestDBlock *DBlock `json:"latestDBlock"`
+	// heights of factomd node
+	DirectoryBlockHeight int64 `json:"directoryBlockHeight"`
+	LeaderHeight         int64 `json:"leaderHeight"`
+	// number of directory blocks, that updates parser is behind factomd's leader height
+	Lag int64 `json:"lag"`
+}
+
+// NewUpdatesStatus calculates lag of updates parser behind factomd
+func NewUpdatesStatus(latest *DBlock, directoryBlockHeight int64, leaderHeight int64) *UpdatesStatus {
+
+	status := &UpdatesStatus{
+		LatestDBlock:         latest,
+		DirectoryBlockHeight: directoryBlockHeight,
+		LeaderHeight:         leaderHeight,
+		Lag:                  leaderHeight,
+	}
+
+	if latest != nil {
+		status.Lag = leaderHeight - latest.Height
+	}
+
+	return status
+
+}
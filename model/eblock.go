@@ -2,16 +2,18 @@ package model
 
 import (
 	"github.com/FactomProject/factom"
+	"github.com/lib/pq"
 )
 
 type EBlock struct {
-	KeyMR               string   `json:"keyMr" gorm:"primary_key;unique;not null"`
-	BlockSequenceNumber int64    `json:"blockSequenceNumber"`
-	ChainID             string   `json:"chainId"`
-	PrevKeyMR           string   `json:"prevKeyMr"`
-	Timestamp           int64    `json:"timestamp"`
-	DBHeight            int64    `json:"dbHeight"`
-	Entries             []*Entry `json:"-" form:"-" query:"-" gorm:"many2many:entries_e_blocks;"`
+	KeyMR               string         `json:"keyMr" gorm:"primary_key;unique;not null"`
+	BlockSequenceNumber int64          `json:"blockSequenceNumber"`
+	ChainID             string         `json:"chainId"`
+	PrevKeyMR           string         `json:"prevKeyMr"`
+	Timestamp           int64          `json:"timestamp"`
+	DBHeight            int64          `json:"dbHeight"`
+	EntryList           pq.StringArray `json:"-" form:"-" query:"-"`
+	Entries             []*Entry       `json:"-" form:"-" query:"-" gorm:"many2many:entries_e_blocks;"`
 }
 
 func NewEBlockFromFactomModel(ebhash string, fe *factom.EBlock) *EBlock {
@@ -29,6 +31,22 @@ func NewEBlockFromFactomModel(ebhash string, fe *factom.EBlock) *EBlock {
 	eblock.PrevKeyMR = fe.Header.PrevKeyMR
 	eblock.Timestamp = fe.Header.Timestamp
 
+	for _, i := range fe.EntryList {
+		eblock.EntryList = append(eblock.EntryList, i.EntryHash)
+	}
+
 	return &eblock
 
 }
+
+// HeaderEquals checks if both entry blocks have the same header
+func (eblock *EBlock) HeaderEquals(other *EBlock) bool {
+
+	return eblock.KeyMR == other.KeyMR &&
+		eblock.BlockSequenceNumber == other.BlockSequenceNumber &&
+		eblock.ChainID == other.ChainID &&
+		eblock.PrevKeyMR == other.PrevKeyMR &&
+		eblock.Timestamp == other.Timestamp &&
+		eblock.DBHeight == other.DBHeight
+
+}
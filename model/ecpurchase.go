@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	ECPurchaseSubmitted = "submitted"
+	ECPurchaseConfirmed = "confirmed"
+	ECPurchaseDropped   = "dropped"
+	ECPurchaseFailed    = "failed"
+)
+
+// ECPurchase is audit log record of FCT→EC conversion made by auto top-up
+type ECPurchase struct {
+	ID         int       `json:"id" gorm:"primary_key"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"-"`
+	FCTAddress string    `json:"fctAddress"`
+	ECAddress  string    `json:"ecAddress"`
+	ECAmount   int64     `json:"ecAmount"`
+	// factoshis converted into EC & paid as fee
+	Factoshis int64 `json:"factoshis"`
+	Fee       int64 `json:"fee"`
+	// factoshis per EC
+	Rate   int64  `json:"rate"`
+	TxID   string `json:"txId,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
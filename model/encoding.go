@@ -0,0 +1,170 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/jinzhu/copier"
+)
+
+const (
+	// Encodings of Content & ExtIDs in API requests and responses
+	EncodingBase64 = "base64"
+	EncodingUTF8   = "utf8"
+	EncodingHex    = "hex"
+
+	// Content & ExtIDs are stored base64 encoded into local DB
+	DefaultEncoding = EncodingBase64
+)
+
+// IsValidEncoding checks if encoding is supported
+func IsValidEncoding(encoding string) bool {
+
+	switch encoding {
+	case EncodingBase64, EncodingUTF8, EncodingHex:
+		return true
+	}
+	return false
+
+}
+
+// ErrInvalidUTF8 is returned if binary data is requested in utf8 encoding
+var ErrInvalidUTF8 = fmt.Errorf("Data is not valid UTF-8, use '%s' or '%s' encoding", EncodingBase64, EncodingHex)
+
+// Encode converts raw data into string using encoding.
+// Binary data, that is not valid UTF-8, can not be encoded as utf8.
+func Encode(data []byte, encoding string) (string, error) {
+
+	switch encoding {
+	case EncodingHex:
+		return hex.EncodeToString(data), nil
+	case EncodingUTF8:
+		if !utf8.Valid(data) {
+			return "", ErrInvalidUTF8
+		}
+		return string(data), nil
+	default:
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+
+}
+
+// Decode converts string encoded with encoding into raw data
+func Decode(s string, encoding string) ([]byte, error) {
+
+	switch encoding {
+	case EncodingHex:
+		return hex.DecodeString(s)
+	case EncodingUTF8:
+		return []byte(s), nil
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return nil, fmt.Errorf("Unsupported encoding '%s'", encoding)
+
+}
+
+// Reencode converts string from one encoding into another
+func Reencode(s string, from string, to string) (string, error) {
+
+	if from == to {
+		return s, nil
+	}
+
+	data, err := Decode(s, from)
+	if err != nil {
+		return "", fmt.Errorf("Can not decode %s string: %s", from, err.Error())
+	}
+
+	return Encode(data, to)
+
+}
+
+// reencodeAll converts Content & ExtIDs from one encoding into another
+func reencodeAll(content string, extIDs []string, from string, to string) (string, []string, error) {
+
+	var err error
+
+	content, err = Reencode(content, from, to)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// empty ExtIDs stay empty, not nil
+	var res []string
+	if extIDs != nil {
+		res = make([]string, 0, len(extIDs))
+	}
+
+	for _, i := range extIDs {
+		extID, err := Reencode(i, from, to)
+		if err != nil {
+			return "", nil, err
+		}
+		res = append(res, extID)
+	}
+
+	return content, res, nil
+
+}
+
+// ConvertEncoding returns copy of entry with Content & ExtIDs converted from one encoding into another
+func (entry *Entry) ConvertEncoding(from string, to string) (*Entry, error) {
+
+	if from == to {
+		return entry, nil
+	}
+
+	entryConverted := &Entry{}
+	copier.Copy(entryConverted, entry)
+
+	content, extIDs, err := reencodeAll(entry.Content, entry.ExtIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	entryConverted.Content = content
+	entryConverted.ExtIDs = extIDs
+
+	return entryConverted, nil
+
+}
+
+// ConvertEncoding returns copy of chain with Content & ExtIDs converted from one encoding into another
+func (chain *Chain) ConvertEncoding(from string, to string) (*Chain, error) {
+
+	if from == to {
+		return chain, nil
+	}
+
+	chainConverted := &Chain{}
+	copier.Copy(chainConverted, chain)
+
+	content, extIDs, err := reencodeAll(chain.Content, chain.ExtIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	chainConverted.Content = content
+	chainConverted.ExtIDs = extIDs
+
+	return chainConverted, nil
+
+}
+
+// ConvertEncoding converts Content & ExtIDs of all chains from one encoding into another
+func (chains *Chains) ConvertEncoding(from string, to string) error {
+
+	for i, v := range chains.Items {
+		chain, err := v.ConvertEncoding(from, to)
+		if err != nil {
+			return err
+		}
+		chains.Items[i] = chain
+	}
+
+	return nil
+
+}
@@ -0,0 +1,27 @@
+package model
+
+// Estimate reflects cost of chain or entry write, calculated without writing
+type Estimate struct {
+	// chain or entry
+	Action    string `json:"action"`
+	ChainID   string `json:"chainId"`
+	EntryHash string `json:"entryHash"`
+	// size of extIds & content in bytes
+	Size int `json:"size"`
+	// EC cost of write, -1 if entry is too large to be written
+	ECCost int `json:"ecCost"`
+	// true if entry is smaller than MaxEntrySize
+	FitsMaxEntrySize bool `json:"fitsMaxEntrySize"`
+	// writes counted into user's writes limit
+	Writes int `json:"writes"`
+}
+
+// EstimateResult reflects cost of one or several writes & whether user's writes limit covers them
+type EstimateResult struct {
+	Items  []*Estimate `json:"items"`
+	ECCost int         `json:"ecCost"`
+	Writes int         `json:"writes"`
+	// remaining writes of user, omitted if user's writes are unlimited
+	RemainingWrites *int `json:"remainingWrites,omitempty"`
+	WithinLimit     bool `json:"withinLimit"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	ExportFormatNDJSON = "ndjson"
+	ExportFormatCSV    = "csv"
+
+	// separator of ExtIDs inside CSV column (base64 strings can't contain it)
+	ExportCSVExtIDsSeparator = ";"
+)
+
+// ExportEntry is a single line of chain export
+type ExportEntry struct {
+	EntryHash  string         `json:"entryHash"`
+	ChainID    string         `json:"chainId"`
+	ExtIDs     pq.StringArray `json:"extIds"`
+	Content    string         `json:"content"`
+	FactomTime *time.Time     `json:"factomTime"`
+	EBlock     *EBlock        `json:"eblock,omitempty"`
+}
+
+// ImportResult is a summary of chain import from NDJSON archive
+type ImportResult struct {
+	ChainID string `json:"chainId"`
+	EBlocks int    `json:"eblocks"`
+	Entries int    `json:"entries"`
+	Synced  bool   `json:"synced"`
+}
+
+// ExportCSVHeader returns columns of CSV export
+func ExportCSVHeader() []string {
+	return []string{"entryHash", "extIds", "content", "factomTime", "eblockKeyMr"}
+}
+
+// CSVRecord converts exported entry into the row of CSV export
+func (e *ExportEntry) CSVRecord() []string {
+
+	var factomTime, keyMR string
+
+	if e.FactomTime != nil {
+		factomTime = e.FactomTime.UTC().Format(time.RFC3339)
+	}
+
+	if e.EBlock != nil {
+		keyMR = e.EBlock.KeyMR
+	}
+
+	return []string{e.EntryHash, strings.Join(e.ExtIDs, ExportCSVExtIDsSeparator), e.Content, factomTime, keyMR}
+
+}
@@ -10,6 +10,10 @@ import (
 const (
 	QueueActionChain = "chain"
 	QueueActionEntry = "entry"
+
+	// states of queue task processing, empty if nothing was sent to Factom yet
+	QueueStateCommitted = "committed"
+	QueueStateRevealed  = "revealed"
 )
 
 type Queue struct {
@@ -27,6 +31,24 @@ type Queue struct {
 	ProcessedAt *time.Time // time when sent to Factom without error, otherwise null
 	NextTryAt   *time.Time // by default null, set when processing failed to postpone next attempt
 	TryCount    int
+	AckStatus   string     // the latest status of processed entry on Factom (see FactomEntry* statuses)
+	NextAckAt   *time.Time // time of the next check of processed entry status on Factom
+	AckTryCount int
+	CommitTxID  string // txid of successful commit
+	State       string // see QueueState* states
+	ECAddress   string // public EC address paid for commit
+	ECCost      int64  // EC paid for commits of task
+}
+
+// QueueActionWrites returns number of writes, that action costs for user's writes limit
+func QueueActionWrites(action string) int {
+
+	if action == QueueActionChain {
+		return 2
+	}
+
+	return 1
+
 }
 
 type QueueParams struct {
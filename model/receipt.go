@@ -0,0 +1,82 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FactomProject/factom"
+)
+
+type Receipt struct {
+	// gorm.Model without ID
+	CreatedAt time.Time  `json:"-" form:"-" query:"-"`
+	UpdatedAt time.Time  `json:"-" form:"-" query:"-"`
+	DeletedAt *time.Time `json:"-" form:"-" query:"-"`
+	// model
+	EntryHash              string       `json:"entryHash" gorm:"primary_key;unique;not null"`
+	MerkleBranch           MerkleBranch `json:"merkleBranch" sql:"type:jsonb"`
+	EntryBlockKeyMR        string       `json:"entryBlockKeyMr"`
+	DirectoryBlockKeyMR    string       `json:"directoryBlockKeyMr"`
+	BitcoinTransactionHash string       `json:"bitcoinTransactionHash,omitempty"`
+	BitcoinBlockHash       string       `json:"bitcoinBlockHash,omitempty"`
+}
+
+// MerkleNode is a single step of Merkle path from entry to directory block
+type MerkleNode struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	Top   string `json:"top"`
+}
+
+// MerkleBranch is stored as JSON into local DB
+type MerkleBranch []MerkleNode
+
+func NewReceiptFromFactomModel(fr *factom.Receipt) *Receipt {
+
+	receipt := Receipt{}
+	receipt.EntryHash = fr.Entry.EntryHash
+	receipt.EntryBlockKeyMR = fr.EntryBlockKeyMR
+	receipt.DirectoryBlockKeyMR = fr.DirectoryBlockKeyMR
+	receipt.BitcoinTransactionHash = fr.BitcoinTransactionHash
+	receipt.BitcoinBlockHash = fr.BitcoinBlockHash
+
+	for _, i := range fr.MerkleBranch {
+		receipt.MerkleBranch = append(receipt.MerkleBranch, MerkleNode{Left: i.Left, Right: i.Right, Top: i.Top})
+	}
+
+	return &receipt
+
+}
+
+// Anchored checks if directory block of receipt is already anchored into Bitcoin
+func (receipt *Receipt) Anchored() bool {
+
+	return receipt.BitcoinBlockHash != ""
+
+}
+
+// Value implements driver.Valuer
+func (branch MerkleBranch) Value() (driver.Value, error) {
+
+	return json.Marshal(branch)
+
+}
+
+// Scan implements sql.Scanner
+func (branch *MerkleBranch) Scan(src interface{}) error {
+
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, branch)
+	case string:
+		return json.Unmarshal([]byte(v), branch)
+	case nil:
+		*branch = nil
+		return nil
+	}
+
+	return fmt.Errorf("Can not scan %T into MerkleBranch", src)
+
+}
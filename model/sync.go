@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+)
+
+// SyncProgress reflects history fetching of chain, that is not synced yet
+type SyncProgress struct {
+	// number of entry blocks & entries stored into local DB
+	EBlocks int `json:"eblocks"`
+	Entries int `json:"entries"`
+	// BlockSequenceNumber of chain.EarliestEntryBlock & chain.LatestEntryBlock (chainhead), if they are fetched
+	EarliestSequence *int64 `json:"earliestSequence"`
+	LatestSequence   *int64 `json:"latestSequence"`
+	// percent of entry blocks between chainhead & the first entry block fetched
+	Percent float64 `json:"percent"`
+	// -1 if chain is waiting for worker
+	WorkerID int `json:"workerId"`
+	// scheduling params of the pool
+	Priority  int        `json:"priority"`
+	Job       *SyncJob   `json:"job,omitempty"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	ETA       *time.Time `json:"eta,omitempty"`
+}
+
+// Remaining returns number of entry blocks, that are not fetched yet, or -1 if unknown
+func (progress *SyncProgress) Remaining() int64 {
+
+	if progress.EarliestSequence != nil {
+		return *progress.EarliestSequence
+	}
+
+	if progress.LatestSequence != nil {
+		return *progress.LatestSequence + 1
+	}
+
+	return -1
+
+}
+
+// NewSyncProgress calculates percent & ETA of history fetching.
+// History is fetched from chainhead (latest) to the first entry block (BlockSequenceNumber=0),
+// so ETA is based on the speed of fetching since chain.SyncStartedAt.
+func NewSyncProgress(chain *Chain, job *SyncJob, earliest *EBlock, latest *EBlock, eblocks int, entries int) *SyncProgress {
+
+	progress := &SyncProgress{EBlocks: eblocks, Entries: entries, WorkerID: chain.WorkerID, StartedAt: chain.SyncStartedAt, Priority: chain.SyncPriority, Job: job}
+
+	if latest != nil {
+		progress.LatestSequence = &latest.BlockSequenceNumber
+	}
+
+	if earliest == nil {
+		return progress
+	}
+
+	progress.EarliestSequence = &earliest.BlockSequenceNumber
+
+	if latest == nil {
+		return progress
+	}
+
+	total := latest.BlockSequenceNumber + 1
+	fetched := latest.BlockSequenceNumber - earliest.BlockSequenceNumber + 1
+	progress.Percent = float64(int(float64(fetched)/float64(total)*10000)) / 100
+
+	if chain.SyncStartedAt == nil || chain.WorkerID <= 0 {
+		return progress
+	}
+
+	// SyncStartSequence is -1 if fetching started from chainhead
+	startSequence := chain.SyncStartSequence
+	if startSequence < 0 {
+		startSequence = latest.BlockSequenceNumber + 1
+	}
+
+	fetchedSinceStart := startSequence - earliest.BlockSequenceNumber
+	elapsed := time.Since(*chain.SyncStartedAt)
+
+	if fetchedSinceStart <= 0 || elapsed <= 0 {
+		return progress
+	}
+
+	eta := time.Now().Add(elapsed / time.Duration(fetchedSinceStart) * time.Duration(earliest.BlockSequenceNumber)).UTC()
+	progress.ETA = &eta
+
+	return progress
+
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+const (
+	SyncJobPending   = "pending"
+	SyncJobRunning   = "running"
+	SyncJobPaused    = "paused"
+	SyncJobFailed    = "failed"
+	SyncJobDone      = "done"
+	SyncJobCancelled = "cancelled"
+)
+
+// SyncJob reflects history fetching of chain by the pool of workers
+type SyncJob struct {
+	CreatedAt time.Time `json:"-" form:"-" query:"-"`
+	UpdatedAt time.Time `json:"updatedAt" form:"-" query:"-"`
+	// model
+	ChainID    string     `json:"-" form:"-" query:"-" gorm:"primary_key;unique;not null"`
+	State      string     `json:"state" form:"-" query:"-"`
+	Attempts   int        `json:"attempts" form:"-" query:"-"`
+	LastError  string     `json:"lastError,omitempty" form:"-" query:"-"`
+	StartedAt  *time.Time `json:"startedAt,omitempty" form:"-" query:"-"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty" form:"-" query:"-"`
+	// number of entry blocks of chain on Factom, cached while chain has no fetched entry blocks, -1 if unknown
+	SizeEstimate int64 `json:"-" form:"-" query:"-" gorm:"not null;default:-1"`
+}
@@ -17,4 +17,8 @@ type User struct {
 	UsageLimit  int      `json:"usageLimit" form:"usageLimit" query:"usageLimit"`
 	Status      int      `json:"-" form:"-" query:"-" gorm:"not null;default:1"`
 	Chains      []*Chain `json:"-" form:"-" query:"-" gorm:"many2many:users_chains;"`
+	// own Es address of user (encrypted) used to pay for user's writes & its public EC address
+	EsAddress string `json:"-" form:"-" query:"-"`
+	ECAddress string `json:"ecAddress,omitempty" form:"-" query:"-"`
+	ECBalance *int64 `json:"ecBalance,omitempty" form:"-" query:"-" sql:"-"`
 }
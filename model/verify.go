@@ -0,0 +1,49 @@
+package model
+
+const (
+	// Types of problems found while verifying local chain data
+	VerifyMissingEBlock   = "missingEBlock"
+	VerifyCorruptEBlock   = "corruptEBlock"
+	VerifyUnknownEBlock   = "unknownEntryList"
+	VerifyMissingEntry    = "missingEntry"
+	VerifyCorruptEntry    = "corruptEntry"
+	VerifyIncompleteChain = "incompleteChain"
+)
+
+// VerifyReport is a result of local chain data verification
+type VerifyReport struct {
+	ChainID  string           `json:"chainId"`
+	EBlocks  int              `json:"eblocks"`
+	Entries  int              `json:"entries"`
+	Problems []*VerifyProblem `json:"problems"`
+}
+
+// VerifyProblem describes single gap or corrupt row found while verification
+type VerifyProblem struct {
+	Type        string `json:"type"`
+	EBlock      string `json:"eblock,omitempty"`
+	EntryHash   string `json:"entryHash,omitempty"`
+	Description string `json:"description"`
+	Repaired    bool   `json:"repaired"`
+	Error       string `json:"error,omitempty"`
+}
+
+// AddProblem adds problem into report and returns it to let caller mark it as repaired
+func (report *VerifyReport) AddProblem(problemType string, eblock string, entryHash string, description string) *VerifyProblem {
+
+	problem := &VerifyProblem{Type: problemType, EBlock: eblock, EntryHash: entryHash, Description: description}
+	report.Problems = append(report.Problems, problem)
+	return problem
+
+}
+
+// SetRepairResult marks problem as repaired or saves repairing error
+func (problem *VerifyProblem) SetRepairResult(err error) {
+
+	if err != nil {
+		problem.Error = err.Error()
+		return
+	}
+	problem.Repaired = true
+
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// ECAddress reflects EC address of wallet
+type ECAddress struct {
+	Address string `json:"address"`
+	Balance int64  `json:"balance"`
+	// EC spent by commits of API
+	Spent int64 `json:"spent"`
+}
+
+// WalletStatus reflects cached balances of wallet & EC needed for queued writes
+type WalletStatus struct {
+	ECAddresses []*ECAddress `json:"ecAddresses"`
+	// total balance of server EC addresses
+	Balance int64 `json:"balance"`
+	// queued writes, that will be paid from server EC addresses
+	QueuedItems int   `json:"queuedItems"`
+	QueuedCost  int64 `json:"queuedCost"`
+	// EC left after paying for queued writes
+	Runway      int64     `json:"runway"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+// WalletAlert is sent to webhook when wallet balance drops below threshold or recovers
+type WalletAlert struct {
+	Type      string    `json:"type"`
+	Address   string    `json:"address,omitempty"`
+	Balance   int64     `json:"balance"`
+	Threshold int64     `json:"threshold"`
+	Resolved  bool      `json:"resolved"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const (
+	WalletAlertLowBalance  = "low_balance"
+	WalletAlertShortRunway = "short_runway"
+)
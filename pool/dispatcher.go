@@ -7,16 +7,21 @@ import (
 var WorkerChannel = make(chan chan Work)
 
 type Collector struct {
-	Work chan Work
-	End  chan bool
+	Work  chan Work
+	End   chan bool
+	Queue *Queue
 }
 
 func StartDispatcher(workerCount int) Collector {
 	var i int
 	var workers []Worker
-	input := make(chan Work) // channel to recieve work
-	end := make(chan bool)   // channel to spin down workers
-	collector := Collector{Work: input, End: end}
+	input := make(chan Work)             // channel to recieve work
+	end := make(chan bool)               // channel to spin down workers
+	done := make(chan Work, workerCount) // channel to recieve finished work
+	running := make(map[string]int)      // running chains with users requested them
+	runningByUser := make(map[int]int)   // number of running chains per user
+	queue := NewQueue()
+	collector := Collector{Work: input, End: end, Queue: queue}
 
 	for i < workerCount {
 		i++
@@ -25,6 +30,7 @@ func StartDispatcher(workerCount int) Collector {
 			ID:            i,
 			Channel:       make(chan Work),
 			WorkerChannel: WorkerChannel,
+			Done:          done,
 			End:           make(chan bool)}
 		worker.Start()
 		workers = append(workers, worker) // store worker
@@ -33,6 +39,12 @@ func StartDispatcher(workerCount int) Collector {
 	// start collector
 	go func() {
 		for {
+			// wait for available worker only if there is something to dispatch
+			var ready chan chan Work
+			if queue.Len() > 0 {
+				ready = WorkerChannel
+			}
+
 			select {
 			case <-end:
 				for _, w := range workers {
@@ -40,8 +52,18 @@ func StartDispatcher(workerCount int) Collector {
 				}
 				return
 			case work := <-input:
-				worker := <-WorkerChannel // wait for available channel
-				worker <- work            // dispatch work to worker
+				// running chain can't be queued again
+				if _, ok := running[work.ID]; !ok {
+					queue.Upsert(work)
+				}
+			case work := <-done:
+				runningByUser[running[work.ID]]--
+				delete(running, work.ID)
+			case worker := <-ready:
+				work, _ := queue.Pop(runningByUser)
+				running[work.ID] = work.RequestedBy
+				runningByUser[work.RequestedBy]++
+				worker <- work // dispatch work to worker
 			}
 		}
 	}()
@@ -0,0 +1,147 @@
+package pool
+
+import (
+	"math"
+	"sync"
+)
+
+// Queue keeps chains waiting for workers.
+// Order of chains depends on running jobs (per-user fairness), so the best chain is selected on every Pop:
+// 1. higher Priority (set by admin) first
+// 2. chains requested by users (while syncing) first
+// 3. chains of users with fewer running jobs first
+// 4. smaller chains (fewer entry blocks remaining) first
+// 5. earlier queued chains first
+type Queue struct {
+	mu    sync.Mutex
+	items map[string]*queueItem
+	seq   int64
+}
+
+type queueItem struct {
+	Work
+	seq int64
+}
+
+func NewQueue() *Queue {
+	return &Queue{items: make(map[string]*queueItem)}
+}
+
+// Upsert adds work into queue or updates params of already queued work.
+// Stopped work is removed from queue. Unknown size (< 0) doesn't overwrite already known one.
+func (q *Queue) Upsert(work Work) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	item, ok := q.items[work.ID]
+
+	if work.Stopped {
+		delete(q.items, work.ID)
+		return
+	}
+	if !ok {
+		q.seq++
+		q.items[work.ID] = &queueItem{Work: work, seq: q.seq}
+		return
+	}
+
+	size := item.Size
+	item.Work = work
+	if work.Size < 0 {
+		item.Size = size
+	}
+
+}
+
+// Pop removes the best work from queue, running is the number of running jobs per user
+func (q *Queue) Pop(running map[int]int) (Work, bool) {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var best *queueItem
+
+	for _, item := range q.items {
+		if best == nil || item.less(best, running) {
+			best = item
+		}
+	}
+
+	if best == nil {
+		return Work{}, false
+	}
+
+	delete(q.items, best.ID)
+
+	return best.Work, true
+
+}
+
+// Contains returns true if work with id is queued
+func (q *Queue) Contains(id string) bool {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	_, ok := q.items[id]
+	return ok
+
+}
+
+// IDs returns ids of queued works
+func (q *Queue) IDs() []string {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ids := make([]string, 0, len(q.items))
+	for id := range q.items {
+		ids = append(ids, id)
+	}
+	return ids
+
+}
+
+// Len returns number of queued works
+func (q *Queue) Len() int {
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.items)
+
+}
+
+func (a *queueItem) less(b *queueItem, running map[int]int) bool {
+
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+
+	aRequested, bRequested := a.RequestedBy > 0, b.RequestedBy > 0
+	if aRequested != bRequested {
+		return aRequested
+	}
+
+	if aRequested && running[a.RequestedBy] != running[b.RequestedBy] {
+		return running[a.RequestedBy] < running[b.RequestedBy]
+	}
+
+	if a.size() != b.size() {
+		return a.size() < b.size()
+	}
+
+	return a.seq < b.seq
+
+}
+
+// unknown size is considered as the largest one
+func (a *queueItem) size() int64 {
+
+	if a.Size < 0 {
+		return math.MaxInt64
+	}
+	return a.Size
+
+}
@@ -10,12 +10,19 @@ type Work struct {
 	ID      string
 	Job     *model.Chain
 	Service service.Service
+	// scheduling params, see Queue
+	Priority    int
+	RequestedBy int
+	Size        int64
+	// work of stopped (paused or cancelled) sync job is removed from queue
+	Stopped bool
 }
 
 type Worker struct {
 	ID            int
 	WorkerChannel chan chan Work
 	Channel       chan Work
+	Done          chan Work
 	End           chan bool
 }
 
@@ -26,6 +33,7 @@ func (w *Worker) Start() {
 			select {
 			case job := <-w.Channel:
 				doWork(job.Job, job.Service, w.ID)
+				w.Done <- job
 			case <-w.End:
 				return
 			}
@@ -40,9 +48,6 @@ func (w *Worker) Stop() {
 
 func doWork(chain *model.Chain, service service.Service, id int) {
 	log.Info("Worker ", id, ", processing ", chain.ChainID)
-	err := service.ParseAllChainEntries(chain, id)
-	if err != nil {
-		log.Error(err)
-		service.ResetChainParsing(chain)
-	}
+	// sync job state & chain params are updated by service
+	service.RunSyncJob(chain, id)
 }
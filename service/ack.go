@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	"github.com/jinzhu/copier"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// interval between status checks of processed entry grows from AckMinInterval till AckMaxInterval
+	AckMinInterval = 5 * time.Second
+	AckMaxInterval = 1 * time.Minute
+	// entry, that is not acknowledged by Factom during this time after processing, is considered dropped & resubmitted
+	AckDropTimeout = 10 * time.Minute
+)
+
+// GetQueueToAck gets processed tasks from queue, which entries status on Factom should be checked
+func (c *Context) GetQueueToAck() []*model.Queue {
+
+	return c.store.GetQueueWhere("processed_at IS NOT NULL AND result IS NOT NULL AND result <> '' AND (next_ack_at IS NULL OR next_ack_at<NOW())")
+
+}
+
+// TrackQueueAck checks status of processed entry on Factom and updates entry status accordingly.
+// Confirmed task is deleted from queue, dropped task is returned to queue for resubmission.
+func (c *Context) TrackQueueAck(queue *model.Queue) error {
+
+	params := &model.QueueParams{}
+	err := json.Unmarshal(queue.Params, &params)
+	if err != nil {
+		return err
+	}
+
+	log.Debug(fmt.Sprintf("Ack tracking: ID=%d, entry=%s, try=%d", queue.ID, queue.Result, queue.AckTryCount))
+
+	// chain ID of new chain is calculated from its first entry, like the one revealed by ProcessQueue()
+	chainID := params.ChainID
+	if queue.Action == model.QueueActionChain {
+		chain := &model.Chain{}
+		copier.Copy(chain, params)
+		chainID = chain.ID()
+	}
+
+	ack, err := factom.EntryRevealACK(queue.Result, "", chainID)
+	if err != nil {
+		// request failure doesn't mean that entry was dropped, so it's checked again later & never resubmitted because of it
+		log.Warn("Ack tracking: Can not check status of entry ", queue.Result, ": ", err)
+		nextAckAt := time.Now().Add(nextAckInterval(queue.AckStatus, queue.AckTryCount))
+		return c.store.UpdateQueueFields(queue, map[string]interface{}{
+			"ack_try_count": queue.AckTryCount + 1,
+			"next_ack_at":   &nextAckAt,
+		})
+	}
+
+	status := ack.EntryData.Status
+
+	switch status {
+	case model.FactomEntryDBlockConfirmed:
+		log.Info("Ack tracking: Entry " + queue.Result + " confirmed")
+		entry := &model.Entry{EntryHash: queue.Result, Status: model.EntryCompleted}
+		if ack.EntryData.BlockDate > 0 {
+			t := time.Unix(ack.EntryData.BlockDate, 0).UTC()
+			entry.FactomTime = &t
+		}
+		err = c.store.UpdateEntry(entry)
+		if err != nil {
+			log.Error(err)
+		}
+		return c.store.DeleteQueue(queue)
+	case model.FactomEntryTransactionACK:
+		if queue.AckStatus != status {
+			log.Debug("Ack tracking: Entry " + queue.Result + " acknowledged")
+		}
+	case model.FactomEntryUnknown, model.FactomEntryNotConfirmed:
+		// entry is resubmitted only if factomd itself doesn't know it for too long
+		if queue.ProcessedAt != nil && time.Since(*queue.ProcessedAt) > AckDropTimeout {
+			return c.resubmitQueue(queue)
+		}
+	}
+
+	nextAckAt := time.Now().Add(nextAckInterval(status, queue.AckTryCount))
+
+	return c.store.UpdateQueueFields(queue, map[string]interface{}{
+		"ack_status":    status,
+		"ack_try_count": queue.AckTryCount + 1,
+		"next_ack_at":   &nextAckAt,
+	})
+
+}
+
+// resubmitQueue returns task, which entry was dropped by Factom, to queue for processing
+func (c *Context) resubmitQueue(queue *model.Queue) error {
+
+	log.Warn("Ack tracking: Entry " + queue.Result + " was dropped by Factom, resubmitting")
+
+	err := c.store.UpdateEntry(&model.Entry{EntryHash: queue.Result, Status: model.EntryQueue})
+	if err != nil {
+		log.Error(err)
+	}
+
+	return c.store.UpdateQueueFields(queue, map[string]interface{}{
+		"processed_at":  nil,
+		"result":        "",
+		"error":         "Entry was dropped by Factom",
+		"next_try_at":   nil,
+		"ack_status":    "",
+		"ack_try_count": 0,
+		"next_ack_at":   nil,
+		"state":         "",
+		"commit_tx_id":  "",
+	})
+
+}
+
+// nextAckInterval returns interval before the next status check of entry.
+// Unacknowledged entry is checked often at first, acknowledged entry waits for directory block.
+func nextAckInterval(status string, tries int) time.Duration {
+
+	if status == model.FactomEntryTransactionACK {
+		return AckMaxInterval
+	}
+
+	interval := AckMinInterval
+	for i := 0; i < tries && interval < AckMaxInterval; i++ {
+		interval *= 2
+	}
+
+	if interval > AckMaxInterval {
+		interval = AckMaxInterval
+	}
+
+	return interval
+
+}
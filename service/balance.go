@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
+	"github.com/FactomProject/factom"
+	"github.com/jinzhu/copier"
+	log "github.com/sirupsen/logrus"
+	"net/http"
+	"time"
+)
+
+// GetWalletStatus is high-level function, that run by api.getWallet()
+// Returns cached balances of server EC addresses, EC spent by API & EC needed for queued writes
+func (c *Context) GetWalletStatus() *model.WalletStatus {
+
+	status := &model.WalletStatus{RefreshedAt: c.wallet.BalancesRefreshedAt()}
+
+	spending := c.store.GetECSpending()
+
+	for _, address := range c.wallet.GetECAddresses() {
+		balance := c.wallet.GetBalance(address)
+		status.ECAddresses = append(status.ECAddresses, &model.ECAddress{Address: address, Balance: balance, Spent: spending[address]})
+		status.Balance += balance
+	}
+
+	status.QueuedItems, status.QueuedCost = c.getQueuedCost()
+	status.Runway = status.Balance - status.QueuedCost
+
+	return status
+
+}
+
+// MonitorBalances refreshes cached balances of wallet, tops up EC addresses with low balance, if enabled,
+// & fires alerts, if balances or runway drop below thresholds set in config
+func (c *Context) MonitorBalances() error {
+
+	err := c.wallet.RefreshBalances()
+
+	status := c.GetWalletStatus()
+
+	log.Info("Wallet: balance=", status.Balance, " EC, queued=", status.QueuedItems, " (", status.QueuedCost, " EC), runway=", status.Runway, " EC")
+
+	c.topUpBalances(status)
+
+	if threshold := c.conf.Wallet.AlertBalance; threshold > 0 {
+		for _, address := range status.ECAddresses {
+			c.checkAlert(&model.WalletAlert{
+				Type:      model.WalletAlertLowBalance,
+				Address:   address.Address,
+				Balance:   address.Balance,
+				Threshold: threshold,
+			}, address.Balance < threshold)
+		}
+	}
+
+	if threshold := c.conf.Wallet.AlertRunway; threshold > 0 {
+		c.checkAlert(&model.WalletAlert{
+			Type:      model.WalletAlertShortRunway,
+			Balance:   status.Runway,
+			Threshold: threshold,
+		}, status.Runway < threshold)
+	}
+
+	return err
+
+}
+
+// getQueuedCost returns number & estimated EC cost of queued writes, that are not committed yet & will be paid from server EC addresses
+func (c *Context) getQueuedCost() (int, int64) {
+
+	var items int
+	var cost int64
+
+	// users with own Es addresses pay for their writes
+	userPays := make(map[int]bool)
+
+	for _, queue := range c.store.GetQueueWhere("processed_at IS NULL") {
+
+		if queue.State == model.QueueStateCommitted {
+			continue
+		}
+
+		if _, ok := userPays[queue.UserID]; !ok && queue.UserID != 0 {
+			user := c.store.GetUser(&model.User{ID: queue.UserID})
+			userPays[queue.UserID] = user != nil && user.EsAddress != "" && !c.conf.Wallet.UserFallback
+		}
+		if userPays[queue.UserID] {
+			continue
+		}
+
+		queueCost, err := estimateQueueCost(queue)
+		if err != nil {
+			log.Debug(err)
+			continue
+		}
+
+		items++
+		cost += queueCost
+
+	}
+
+	return items, cost
+
+}
+
+// estimateQueueCost returns EC cost of queued write
+func estimateQueueCost(queue *model.Queue) (int64, error) {
+
+	params := &model.QueueParams{}
+	if err := json.Unmarshal(queue.Params, &params); err != nil {
+		return 0, err
+	}
+
+	entry := &model.Entry{}
+	copier.Copy(entry, params)
+
+	cost, err := factom.EntryCost(entry.ConvertToFactomModel())
+	if err != nil {
+		return 0, err
+	}
+
+	switch queue.Action {
+	case model.QueueActionChain:
+		return int64(cost) + wallet.ChainECCost, nil
+	case model.QueueActionEntry:
+		return int64(cost), nil
+	}
+
+	return 0, fmt.Errorf("Wallet: Can not estimate cost of queue action=%s", queue.Action)
+
+}
+
+// checkAlert fires alert once its condition becomes true & resolves it once condition becomes false
+func (c *Context) checkAlert(alert *model.WalletAlert, firing bool) {
+
+	key := alert.Type + alert.Address
+
+	if firing == c.alerts[key] {
+		return
+	}
+	c.alerts[key] = firing
+
+	alert.Resolved = !firing
+	alert.CreatedAt = time.Now().UTC()
+
+	switch alert.Type {
+	case model.WalletAlertLowBalance:
+		alert.Message = fmt.Sprintf("Balance of EC address %s is %d EC, threshold is %d EC", alert.Address, alert.Balance, alert.Threshold)
+	case model.WalletAlertShortRunway:
+		alert.Message = fmt.Sprintf("EC left after paying for queued writes is %d EC, threshold is %d EC", alert.Balance, alert.Threshold)
+	}
+
+	if firing {
+		log.Warn("Wallet alert: ", alert.Message)
+	} else {
+		log.Info("Wallet alert resolved: ", alert.Message)
+	}
+
+	if c.conf.Wallet.AlertWebhook != "" {
+		if err := sendAlert(c.conf.Wallet.AlertWebhook, alert); err != nil {
+			log.Error("Wallet alert: Can not send alert to webhook: ", err)
+		}
+	}
+
+}
+
+// sendAlert POSTs alert as JSON to webhook URL
+func sendAlert(url string, alert *model.WalletAlert) error {
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Post(url, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+
+}
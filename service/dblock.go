@@ -0,0 +1,156 @@
+package service
+
+import (
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	log "github.com/sirupsen/logrus"
+)
+
+// ParseNewDBlocks is high-level function, that run by updates parser.
+// Every directory block after the latest processed one (up to height) is fetched once,
+// only tracked chains, which entry blocks are included into directory block, are parsed.
+// If no directory blocks were processed yet, all tracked chains are checked by chainheads.
+func (c *Context) ParseNewDBlocks(height int64) error {
+
+	latest := c.store.GetLatestDBlock()
+
+	if latest == nil {
+		log.Info("Updates parser: No processed DBlocks found, checking chainheads of tracked chains")
+		for _, chain := range c.store.GetChains(&model.Chain{Status: model.ChainCompleted}) {
+			err := c.ParseNewChainEntries(chain)
+			if err != nil {
+				log.Error(err)
+			}
+		}
+		// all chains are up to date, so next DBlocks are parsed after this one
+		dblock, err := c.fetchDBlock(height)
+		if err != nil {
+			return err
+		}
+		return c.store.SaveDBlock(dblock)
+	}
+
+	// blocks produced while API was down are parsed one by one
+	if missed := height - latest.Height; missed > 1 {
+		log.Info("Updates parser: Catching up ", missed, " DBlocks since DBlock ", latest.Height)
+	}
+
+	for h := latest.Height + 1; h <= height; h++ {
+
+		dblock, err := c.fetchDBlock(h)
+		if err != nil {
+			return err
+		}
+
+		// processed dblocks were replaced on Factom, so local data is rolled back to the common dblock & parsed again
+		if dblock.PrevKeyMR != latest.KeyMR {
+			log.Warn("Updates parser: DBlock ", h, " does not follow processed DBlock ", latest.Height, ", rolling back")
+			latest, err = c.rollbackDBlocks(latest.Height)
+			if err != nil {
+				return err
+			}
+			h = latest.Height
+			continue
+		}
+
+		err = c.parseDBlock(dblock)
+		if err != nil {
+			return err
+		}
+
+		latest = dblock
+
+	}
+
+	return nil
+
+}
+
+// GetLatestDBlock returns the latest directory block processed by updates parser
+func (c *Context) GetLatestDBlock() *model.DBlock {
+	return c.store.GetLatestDBlock()
+}
+
+// GetUpdatesStatus is high-level function, that run by api.getUpdatesStatus()
+// Returns the latest processed directory block & lag behind factomd's leader height
+func (c *Context) GetUpdatesStatus() (*model.UpdatesStatus, error) {
+
+	heights, err := factom.GetHeights()
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewUpdatesStatus(c.store.GetLatestDBlock(), heights.DirectoryBlockHeight, heights.LeaderHeight), nil
+
+}
+
+// parseDBlock parses new entry blocks of tracked chains & stores directory block as processed.
+// Failed chain doesn't hold back other chains: it's marked as unsynced,
+// so missed entry blocks are parsed from chainhead by history fetching (see ParseAllChainEntries()).
+func (c *Context) parseDBlock(dblock *model.DBlock) error {
+
+	log.Debug("Updates parser: Parsing DBlock ", dblock.Height, " with ", len(dblock.EntryBlockList), " entry blocks")
+
+	keyMRs := make(map[string]string)
+	var chainIDs []string
+	for _, item := range dblock.EntryBlockList {
+		keyMRs[item.ChainID] = item.KeyMR
+		chainIDs = append(chainIDs, item.ChainID)
+	}
+
+	for _, chain := range c.store.GetChainsByIDs(chainIDs, &model.Chain{Status: model.ChainCompleted}) {
+
+		keyMR := keyMRs[chain.ChainID]
+
+		// chain without known chainhead is parsed only by history fetching
+		// entry block may be already stored (e.g. parsed from chainhead or imported)
+		if chain.LatestEntryBlock == "" || c.store.GetEBlock(&model.EBlock{KeyMR: keyMR}) != nil {
+			continue
+		}
+
+		log.Debug("Updates parser: Chain " + chain.ChainID + " updated, parsing new entries")
+
+		err := c.parseChainUpdates(chain, keyMR)
+		if err != nil {
+			log.Error("Updates parser: Parsing updates of chain ", chain.ChainID, " failed, sending it to history fetching: ", err)
+			c.requeueChainSync(chain)
+		}
+
+	}
+
+	return c.store.SaveDBlock(dblock)
+
+}
+
+// requeueChainSync marks chain as unsynced, so its sync job becomes pending again (see GetChainsToSync())
+func (c *Context) requeueChainSync(chain *model.Chain) {
+
+	f := false
+	err := c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, Synced: &f})
+	if err != nil {
+		log.Error(err)
+	}
+
+}
+
+// fetchDBlock fetches directory block by height from Factom
+func (c *Context) fetchDBlock(height int64) (*model.DBlock, error) {
+
+	resp, err := factom.GetBlockByHeightRaw("d", height)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.DBlock == nil {
+		return nil, fmt.Errorf("Updates parser: DBlock %d not found on Factom", height)
+	}
+
+	data, err := resp.DBlock.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDBlockFromJSON(data)
+
+}
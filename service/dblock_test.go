@@ -0,0 +1,65 @@
+package service
+
+import (
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	"testing"
+)
+
+func TestParseNewDBlocksSkipsFailedChain(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	failedID, failedFirst := newTestChain("failed")
+	updatedID, updatedFirst := newTestChain("updated")
+
+	a0 := f.addEBlock(failedID, factom.ZeroHash, 9, failedFirst, 1)
+	b0 := f.addEBlock(updatedID, factom.ZeroHash, 9, updatedFirst, 1)
+	f.addDBlock(9, a0, b0)
+
+	s := newMemStore()
+	s.CreateChain(&model.Chain{ChainID: failedID, Status: model.ChainCompleted})
+	s.CreateChain(&model.Chain{ChainID: updatedID, Status: model.ChainCompleted})
+	s.SaveDBlock(&model.DBlock{Height: 9, KeyMR: f.dblocks[9].KeyMR})
+
+	c := &Context{store: s}
+
+	storeTestEBlocks(t, c, failedID, a0)
+	storeTestEBlocks(t, c, updatedID, b0)
+
+	a1 := f.addEBlock(failedID, a0, 10, nil, 1)
+	b1 := f.addEBlock(updatedID, b0, 10, nil, 1)
+	f.addDBlock(10, a1, b1)
+
+	// entry of the first chain of directory block fails
+	s.hooks.entryCalls = 0
+	s.hooks.failEntryAt = 1
+
+	if err := c.ParseNewDBlocks(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if latest := s.GetLatestDBlock(); latest == nil || latest.Height != 10 {
+		t.Fatalf("DBlock 10 is not stored as processed, latest is %v", latest)
+	}
+
+	failed := s.GetChain(&model.Chain{ChainID: failedID})
+
+	if failed.LatestEntryBlock != a0 {
+		t.Errorf("LatestEntryBlock of failed chain is %s, expected %s", failed.LatestEntryBlock, a0)
+	}
+
+	if failed.Synced == nil || *failed.Synced {
+		t.Error("failed chain is not marked as unsynced")
+	}
+
+	if latest := s.GetChain(&model.Chain{ChainID: updatedID}).LatestEntryBlock; latest != b1 {
+		t.Errorf("LatestEntryBlock of updated chain is %s, expected %s", latest, b1)
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: b1}) == nil {
+		t.Errorf("EntryBlock %s of updated chain is not stored", b1)
+	}
+
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
+)
+
+// EstimateWrites is high-level function, that run by api.estimate()
+// Entries without ChainID are estimated as first entries of new chains.
+// Nothing is written into DB or queue.
+func (c *Context) EstimateWrites(entries []*model.Entry, user *model.User) *model.EstimateResult {
+
+	res := &model.EstimateResult{}
+
+	for _, entry := range entries {
+		entry = entry.Base64Decode()
+		action := model.QueueActionEntry
+		if entry.ChainID == "" {
+			action = model.QueueActionChain
+			chain := &model.Chain{ExtIDs: entry.ExtIDs, Content: entry.Content}
+			entry.ChainID = chain.ID()
+		}
+		estimate := estimateWrite(entry, action)
+		res.Items = append(res.Items, estimate)
+		if estimate.ECCost > 0 {
+			res.ECCost += estimate.ECCost
+		}
+		res.Writes += estimate.Writes
+	}
+
+	res.WithinLimit = true
+
+	if user.UsageLimit != 0 {
+		remaining := user.UsageLimit - user.Usage
+		res.RemainingWrites = &remaining
+		res.WithinLimit = remaining >= res.Writes
+	}
+
+	return res
+
+}
+
+// estimateWrite calculates cost of base64-decoded entry with ChainID, that is written by action (as new chain or entry)
+func estimateWrite(entry *model.Entry, action string) *model.Estimate {
+
+	estimate := &model.Estimate{Action: action}
+
+	estimate.ChainID = entry.ChainID
+	estimate.EntryHash = entry.Hash()
+	estimate.Size = entry.Size()
+	estimate.FitsMaxEntrySize, _ = entry.Fit10KB()
+	estimate.Writes = model.QueueActionWrites(estimate.Action)
+
+	estimate.ECCost = entry.ECCost()
+	if !estimate.FitsMaxEntrySize {
+		estimate.ECCost = -1
+	}
+	if estimate.ECCost > 0 && estimate.Action == model.QueueActionChain {
+		estimate.ECCost += wallet.ChainECCost
+	}
+
+	return estimate
+
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/FactomProject/factom"
+	log "github.com/sirupsen/logrus"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// JSON-RPC 2.0 errors, that factomd node without batch support responds to batch request with
+	jsonRPCParseError     = -32700
+	jsonRPCInvalidRequest = -32600
+)
+
+// errBatchUnsupported is returned if factomd node rejects batch request as unparsable or invalid JSON-RPC request
+var errBatchUnsupported = fmt.Errorf("factomd does not support JSON-RPC batch requests")
+
+// factomdClient keeps HTTP client & URL of factomd API, that are used for batch requests
+type factomdClient struct {
+	once   sync.Once
+	client *http.Client
+	url    string
+	err    error
+}
+
+// factomdRequests sends requests to factomd and returns responses in the order of requests.
+// Requests are grouped into JSON-RPC 2.0 batches of conf.Factom.BatchSize,
+// max EntryFetchConcurrency batches (or single requests) are sent concurrently.
+// If factomd node does not support batches, single requests are used from now on.
+func (c *Context) factomdRequests(reqs []*factom.JSON2Request) ([]*factom.JSON2Response, error) {
+
+	resps := make([]*factom.JSON2Response, len(reqs))
+
+	size := 1
+	if c.batchEnabled() {
+		size = c.conf.Factom.BatchSize
+	}
+
+	var chunks [][]*factom.JSON2Request
+	for i := 0; i < len(reqs); i += size {
+		end := i + size
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		chunks = append(chunks, reqs[i:end])
+	}
+
+	errs := make([]error, len(chunks))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, EntryFetchConcurrency)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, chunk []*factom.JSON2Request) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			offset := i * size
+			chunkResps, err := c.factomdChunk(chunk)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			copy(resps[offset:], chunkResps)
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resps, nil
+
+}
+
+// factomdChunk sends requests as one batch, or one by one if batch is not possible
+func (c *Context) factomdChunk(reqs []*factom.JSON2Request) ([]*factom.JSON2Response, error) {
+
+	if len(reqs) > 1 && c.batchEnabled() {
+		resps, err := c.sendFactomdBatch(reqs)
+		if err != errBatchUnsupported {
+			return resps, err
+		}
+		log.Warn(err, ", falling back to single requests")
+		atomic.StoreInt32(&c.batchUnsupported, 1)
+	}
+
+	resps := make([]*factom.JSON2Response, len(reqs))
+
+	for i, req := range reqs {
+		resp, err := factom.SendFactomdRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		resps[i] = resp
+	}
+
+	return resps, nil
+
+}
+
+// batchEnabled returns true if batch size > 1 is configured and factomd node has not rejected batches before
+func (c *Context) batchEnabled() bool {
+	return c.conf != nil && c.conf.Factom.BatchSize > 1 && atomic.LoadInt32(&c.batchUnsupported) == 0
+}
+
+// batchClient returns HTTP client & URL of factomd API, created once from factom.RpcConfig.
+// factom package does not expose its HTTP client, so the client is built from the same settings
+// (server, TLS & credentials), that are set into factom package on start & used by factom.SendFactomdRequest().
+func (c *Context) batchClient() (*http.Client, string, error) {
+
+	c.factomd.once.Do(func() {
+
+		conf := factom.RpcConfig
+
+		c.factomd.client = &http.Client{Timeout: time.Second * 30}
+		scheme := "http"
+		host := conf.FactomdServer
+
+		if conf.FactomdTLSEnable {
+			caCert, err := ioutil.ReadFile(conf.FactomdTLSCertFile)
+			if err != nil {
+				c.factomd.err = err
+				return
+			}
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+			c.factomd.client.Transport = &http.Transport{TLSClientConfig: &tls.Config{RootCAs: caCertPool}}
+			scheme = "https"
+		} else if index := strings.Index(host, "://"); index != -1 {
+			scheme = host[0:index]
+			host = host[index+3:]
+		}
+
+		c.factomd.url = fmt.Sprintf("%s://%s/v2", scheme, host)
+
+	})
+
+	return c.factomd.client, c.factomd.url, c.factomd.err
+
+}
+
+// sendFactomdBatch sends JSON-RPC 2.0 batch request to factomd and returns responses in the order of requests
+func (c *Context) sendFactomdBatch(reqs []*factom.JSON2Request) ([]*factom.JSON2Response, error) {
+
+	client, url, err := c.batchClient()
+	if err != nil {
+		return nil, err
+	}
+
+	j, err := json.Marshal(reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	re, err := http.NewRequest("POST", url, bytes.NewBuffer(j))
+	if err != nil {
+		return nil, err
+	}
+
+	re.SetBasicAuth(factom.RpcConfig.FactomdRPCUser, factom.RpcConfig.FactomdRPCPassword)
+	re.Header.Add("Content-Type", "application/json")
+
+	resp, err := client.Do(re)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("Factomd username/password incorrect")
+	}
+
+	var batch []*factom.JSON2Response
+	if err := json.Unmarshal(body, &batch); err != nil {
+		// factomd node without batch support responds with a single JSON-RPC error,
+		// anything else (e.g. error page of proxy) is a temporary failure & batches are not disabled
+		single := factom.NewJSON2Response()
+		if json.Unmarshal(body, single) == nil && single.Error != nil &&
+			(single.Error.Code == jsonRPCParseError || single.Error.Code == jsonRPCInvalidRequest) {
+			return nil, errBatchUnsupported
+		}
+		return nil, fmt.Errorf("Invalid factomd response to batch request, HTTP status %d", resp.StatusCode)
+	}
+
+	// responses of batch may come in any order
+	byID := make(map[string]*factom.JSON2Response)
+	for _, r := range batch {
+		byID[fmt.Sprint(r.ID)] = r
+	}
+
+	resps := make([]*factom.JSON2Response, len(reqs))
+	for i, req := range reqs {
+		r, ok := byID[fmt.Sprint(req.ID)]
+		if !ok {
+			return nil, fmt.Errorf("factomd batch response does not contain response for request %v", req.ID)
+		}
+		resps[i] = r
+	}
+
+	return resps, nil
+
+}
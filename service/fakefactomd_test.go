@@ -0,0 +1,241 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+)
+
+// fakeFactomd is factomd API v2 simulated by httptest server.
+// It serves directory blocks, entry blocks & entries, that can be replaced by tests to simulate reorgs.
+type fakeFactomd struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	dblocks map[int64]*fakeDBlock
+	eblocks map[string]*factom.EBlock
+	entries map[string]*factom.Entry
+	heads   map[string]string
+	// keymrs of requested entry blocks in the order of requests
+	requested []string
+	nonce     int
+}
+
+// fakeDBlock is directory block in the format of factomd "dblock-by-height" response
+type fakeDBlock struct {
+	Header struct {
+		DBHeight  int64  `json:"dbheight"`
+		PrevKeyMR string `json:"prevkeymr"`
+		Timestamp int64  `json:"timestamp"`
+	} `json:"header"`
+	KeyMR     string              `json:"keymr"`
+	DBEntries []model.DBlockEntry `json:"dbentries"`
+}
+
+// newFakeFactomd starts fake factomd & points factom package to it
+func newFakeFactomd() *fakeFactomd {
+
+	f := &fakeFactomd{
+		dblocks: make(map[int64]*fakeDBlock),
+		eblocks: make(map[string]*factom.EBlock),
+		entries: make(map[string]*factom.Entry),
+		heads:   make(map[string]string),
+	}
+
+	f.server = httptest.NewServer(http.HandlerFunc(f.handle))
+	factom.SetFactomdServer(f.server.URL)
+
+	return f
+
+}
+
+func (f *fakeFactomd) Close() {
+	f.server.Close()
+}
+
+// newChain returns chain ID & first entry of the new chain
+func newTestChain(name string) (string, *factom.Entry) {
+
+	first := &factom.Entry{ExtIDs: [][]byte{[]byte("test"), []byte(name)}, Content: []byte("first entry of " + name)}
+	chain := factom.NewChain(first)
+	first.ChainID = chain.ChainID
+
+	return chain.ChainID, first
+
+}
+
+// addEBlock appends entry block with first entry (if not nil) & n more entries to chain & returns its keymr.
+// Entry block is linked to prevKeyMR, not to the current chainhead, so blocks after prevKeyMR may be replaced.
+func (f *fakeFactomd) addEBlock(chainID string, prevKeyMR string, dbHeight int64, first *factom.Entry, n int) string {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nonce++
+
+	eb := &factom.EBlock{}
+	eb.Header.ChainID = chainID
+	eb.Header.PrevKeyMR = prevKeyMR
+	eb.Header.DBHeight = dbHeight
+	eb.Header.Timestamp = 1500000000 + dbHeight*600
+	if prev, ok := f.eblocks[prevKeyMR]; ok {
+		eb.Header.BlockSequenceNumber = prev.Header.BlockSequenceNumber + 1
+	}
+
+	var entries []*factom.Entry
+	if first != nil {
+		entries = append(entries, first)
+	}
+	for i := 0; i < n; i++ {
+		entries = append(entries, &factom.Entry{ChainID: chainID, Content: []byte(fmt.Sprintf("entry %d of eblock %d", i, f.nonce))})
+	}
+
+	for _, e := range entries {
+		hash := hex.EncodeToString(e.Hash())
+		f.entries[hash] = e
+		eb.EntryList = append(eb.EntryList, factom.EBEntry{EntryHash: hash, Timestamp: eb.Header.Timestamp})
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s-%d-%d", chainID, eb.Header.BlockSequenceNumber, f.nonce)))
+	keyMR := hex.EncodeToString(sum[:])
+
+	f.eblocks[keyMR] = eb
+	f.heads[chainID] = keyMR
+
+	return keyMR
+
+}
+
+// addDBlock adds directory block with entry blocks keyMRs at height (replacing existing one) & returns its keymr.
+// Directory block is linked to the current directory block at height-1.
+func (f *fakeFactomd) addDBlock(height int64, keyMRs ...string) string {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nonce++
+
+	db := &fakeDBlock{}
+	db.Header.DBHeight = height
+	db.Header.Timestamp = 25000000 + height*10
+	if prev, ok := f.dblocks[height-1]; ok {
+		db.Header.PrevKeyMR = prev.KeyMR
+	}
+
+	for _, keyMR := range keyMRs {
+		db.DBEntries = append(db.DBEntries, model.DBlockEntry{ChainID: f.eblocks[keyMR].Header.ChainID, KeyMR: keyMR})
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("dblock-%d-%d", height, f.nonce)))
+	db.KeyMR = hex.EncodeToString(sum[:])
+
+	f.dblocks[height] = db
+
+	return db.KeyMR
+
+}
+
+// requests returns keymrs of entry blocks requested since the previous call
+func (f *fakeFactomd) requests() []string {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := f.requested
+	f.requested = nil
+
+	return res
+
+}
+
+func (f *fakeFactomd) handle(w http.ResponseWriter, r *http.Request) {
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	var batch []*factom.JSON2Request
+	if err := json.Unmarshal(body, &batch); err == nil {
+		var resps []*factom.JSON2Response
+		for _, req := range batch {
+			resps = append(resps, f.respond(req))
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	req := &factom.JSON2Request{}
+	if err := json.Unmarshal(body, req); err != nil {
+		json.NewEncoder(w).Encode(errorResponse(nil, jsonRPCParseError, "Parse error"))
+		return
+	}
+
+	json.NewEncoder(w).Encode(f.respond(req))
+
+}
+
+func (f *fakeFactomd) respond(req *factom.JSON2Request) *factom.JSON2Response {
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	params := make(map[string]string)
+	json.Unmarshal(req.Params, &params)
+
+	var result interface{}
+
+	switch req.Method {
+	case "entry-block":
+		f.requested = append(f.requested, params["keymr"])
+		eb, ok := f.eblocks[params["keymr"]]
+		if !ok {
+			return errorResponse(req.ID, -32008, "Block not found")
+		}
+		result = eb
+	case "entry":
+		e, ok := f.entries[params["hash"]]
+		if !ok {
+			return errorResponse(req.ID, -32008, "Entry not found")
+		}
+		result = e
+	case "dblock-by-height":
+		height := struct {
+			Height int64 `json:"height"`
+		}{}
+		json.Unmarshal(req.Params, &height)
+		db, ok := f.dblocks[height.Height]
+		if !ok {
+			return errorResponse(req.ID, -32008, "Block not found")
+		}
+		result = map[string]interface{}{"dblock": db}
+	case "chain-head":
+		head, ok := f.heads[params["chainid"]]
+		if !ok {
+			return errorResponse(req.ID, -32009, "Missing Chain Head")
+		}
+		result = map[string]interface{}{"chainhead": head, "chaininprocesslist": false}
+	default:
+		return errorResponse(req.ID, -32601, "Method not found")
+	}
+
+	resp := factom.NewJSON2Response()
+	resp.ID = req.ID
+	resp.Result, _ = json.Marshal(result)
+
+	return resp
+
+}
+
+func errorResponse(id interface{}, code int, message string) *factom.JSON2Response {
+
+	resp := factom.NewJSON2Response()
+	resp.ID = id
+	resp.Error = factom.NewJSONError(code, message, nil)
+
+	return resp
+
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"reflect"
+	"sort"
+)
+
+// memStore is in-memory store.Store for tests.
+// Only methods used by tested code are implemented, others panic through embedded nil store.Store.
+// WithTx works on a copy of data, that replaces data only if fn succeeds, like DB transaction does.
+type memStore struct {
+	store.Store
+	data  *memData
+	inTx  bool
+	hooks *memHooks
+}
+
+// memData keeps rows of memStore tables
+type memData struct {
+	chains   map[string]*model.Chain
+	eblocks  map[string]*model.EBlock
+	entries  map[string]*model.Entry
+	bindings map[string][]string
+	syncJobs map[string]*model.SyncJob
+	dblocks  map[int64]*model.DBlock
+}
+
+// memHooks injects failures into memStore
+type memHooks struct {
+	// the N-th call of CreateEntry fails, if failEntryAt > 0
+	failEntryAt int
+	entryCalls  int
+}
+
+func newMemStore() *memStore {
+
+	return &memStore{
+		data: &memData{
+			chains:   make(map[string]*model.Chain),
+			eblocks:  make(map[string]*model.EBlock),
+			entries:  make(map[string]*model.Entry),
+			bindings: make(map[string][]string),
+			syncJobs: make(map[string]*model.SyncJob),
+			dblocks:  make(map[int64]*model.DBlock),
+		},
+		hooks: &memHooks{},
+	}
+
+}
+
+func (d *memData) clone() *memData {
+
+	res := &memData{
+		chains:   make(map[string]*model.Chain),
+		eblocks:  make(map[string]*model.EBlock),
+		entries:  make(map[string]*model.Entry),
+		bindings: make(map[string][]string),
+		syncJobs: make(map[string]*model.SyncJob),
+		dblocks:  make(map[int64]*model.DBlock),
+	}
+
+	for k, v := range d.chains {
+		c := *v
+		res.chains[k] = &c
+	}
+	for k, v := range d.eblocks {
+		c := *v
+		res.eblocks[k] = &c
+	}
+	for k, v := range d.entries {
+		c := *v
+		res.entries[k] = &c
+	}
+	for k, v := range d.bindings {
+		res.bindings[k] = append([]string(nil), v...)
+	}
+	for k, v := range d.syncJobs {
+		c := *v
+		res.syncJobs[k] = &c
+	}
+	for k, v := range d.dblocks {
+		c := *v
+		res.dblocks[k] = &c
+	}
+
+	return res
+
+}
+
+// updateNonZero copies non-zero fields of src into dst, like gorm Updates() with struct does
+func updateNonZero(dst interface{}, src interface{}) {
+
+	d := reflect.ValueOf(dst).Elem()
+	s := reflect.ValueOf(src).Elem()
+
+	for i := 0; i < s.NumField(); i++ {
+		f := s.Field(i)
+		if !reflect.DeepEqual(f.Interface(), reflect.Zero(f.Type()).Interface()) {
+			d.Field(i).Set(f)
+		}
+	}
+
+}
+
+func (s *memStore) WithTx(fn func(tx store.Store) error) error {
+
+	if s.inTx {
+		return fn(s)
+	}
+
+	tx := &memStore{data: s.data.clone(), inTx: true, hooks: s.hooks}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	s.data = tx.data
+
+	return nil
+
+}
+
+func (s *memStore) GetChain(chain *model.Chain) *model.Chain {
+
+	res, ok := s.data.chains[chain.ChainID]
+	if !ok {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+func (s *memStore) CreateChain(chain *model.Chain) error {
+
+	if _, ok := s.data.chains[chain.ChainID]; ok {
+		return fmt.Errorf("DB: Chain %s already exists", chain.ChainID)
+	}
+	c := *chain
+	s.data.chains[chain.ChainID] = &c
+	return nil
+
+}
+
+func (s *memStore) UpdateChain(chain *model.Chain) error {
+
+	res, ok := s.data.chains[chain.ChainID]
+	if !ok {
+		return fmt.Errorf("DB: Updating chain failed")
+	}
+	updateNonZero(res, chain)
+	return nil
+
+}
+
+func (s *memStore) GetEBlock(eblock *model.EBlock) *model.EBlock {
+
+	res, ok := s.data.eblocks[eblock.KeyMR]
+	if !ok {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+func (s *memStore) CreateEBlock(eblock *model.EBlock) error {
+
+	if res, ok := s.data.eblocks[eblock.KeyMR]; ok {
+		res.EntryList = eblock.EntryList
+		return nil
+	}
+	c := *eblock
+	s.data.eblocks[eblock.KeyMR] = &c
+	return nil
+
+}
+
+func (s *memStore) CreateEntry(entry *model.Entry) error {
+
+	s.hooks.entryCalls++
+	if s.hooks.failEntryAt > 0 && s.hooks.entryCalls == s.hooks.failEntryAt {
+		return fmt.Errorf("DB: Creating entry %s failed", entry.EntryHash)
+	}
+
+	if res, ok := s.data.entries[entry.EntryHash]; ok {
+		res.Status = entry.Status
+		if entry.FactomTime != nil {
+			res.FactomTime = entry.FactomTime
+		}
+		return nil
+	}
+	c := *entry
+	s.data.entries[entry.EntryHash] = &c
+	return nil
+
+}
+
+func (s *memStore) BindEntryToEBlock(entry *model.Entry, eblock *model.EBlock) error {
+
+	for _, hash := range s.data.bindings[eblock.KeyMR] {
+		if hash == entry.EntryHash {
+			return nil
+		}
+	}
+	s.data.bindings[eblock.KeyMR] = append(s.data.bindings[eblock.KeyMR], entry.EntryHash)
+	return nil
+
+}
+
+func (s *memStore) CreateEBlockWithEntries(eblock *model.EBlock, entries []*model.Entry) error {
+
+	return s.WithTx(func(tx store.Store) error {
+		if err := tx.CreateEBlock(eblock); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := tx.CreateEntry(entry); err != nil {
+				return err
+			}
+			if err := tx.BindEntryToEBlock(entry, eblock); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+}
+
+func (s *memStore) GetSyncJob(job *model.SyncJob) *model.SyncJob {
+
+	res, ok := s.data.syncJobs[job.ChainID]
+	if !ok {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+func (s *memStore) GetChainsByIDs(chainIDs []string, chain *model.Chain) []*model.Chain {
+
+	res := []*model.Chain{}
+	for _, id := range chainIDs {
+		if c, ok := s.data.chains[id]; ok && (chain.Status == "" || c.Status == chain.Status) {
+			found := *c
+			res = append(res, &found)
+		}
+	}
+	return res
+
+}
+
+func (s *memStore) GetChainsWithEBlocksAfter(dbHeight int64) []*model.Chain {
+
+	ids := make(map[string]bool)
+	for _, eb := range s.data.eblocks {
+		if eb.DBHeight > dbHeight {
+			ids[eb.ChainID] = true
+		}
+	}
+
+	var chainIDs []string
+	for id := range ids {
+		chainIDs = append(chainIDs, id)
+	}
+	sort.Strings(chainIDs)
+
+	return s.GetChainsByIDs(chainIDs, &model.Chain{})
+
+}
+
+func (s *memStore) GetChainLatestEBlock(chain *model.Chain, maxDBHeight int64) *model.EBlock {
+
+	var res *model.EBlock
+	for _, eb := range s.data.eblocks {
+		if eb.ChainID == chain.ChainID && eb.DBHeight <= maxDBHeight && (res == nil || eb.BlockSequenceNumber > res.BlockSequenceNumber) {
+			res = eb
+		}
+	}
+	if res == nil {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+// deleteEBlocks deletes entry blocks of chain with sequence number > after & completed entries, that are not bound to other entry blocks
+func (s *memStore) deleteEBlocks(chainID string, after int64) {
+
+	unbound := make(map[string]bool)
+
+	for keyMR, eb := range s.data.eblocks {
+		if eb.ChainID != chainID || eb.BlockSequenceNumber <= after {
+			continue
+		}
+		for _, hash := range s.data.bindings[keyMR] {
+			unbound[hash] = true
+		}
+		delete(s.data.bindings, keyMR)
+		delete(s.data.eblocks, keyMR)
+	}
+
+	for _, hashes := range s.data.bindings {
+		for _, hash := range hashes {
+			delete(unbound, hash)
+		}
+	}
+
+	for hash := range unbound {
+		if entry, ok := s.data.entries[hash]; ok && entry.Status == model.EntryCompleted {
+			delete(s.data.entries, hash)
+		}
+	}
+
+}
+
+func (s *memStore) DeleteChainData(chain *model.Chain) error {
+
+	res, ok := s.data.chains[chain.ChainID]
+	if !ok {
+		return nil
+	}
+
+	s.deleteEBlocks(chain.ChainID, -1)
+
+	f := false
+	res.Synced = &f
+	res.EarliestEntryBlock = ""
+	res.LatestEntryBlock = chain.LatestEntryBlock
+	res.WorkerID = -1
+	res.SentToPool = &f
+	res.SyncStartedAt = nil
+	res.SyncStartSequence = -1
+
+	return nil
+
+}
+
+func (s *memStore) RollbackChain(chain *model.Chain, ancestor *model.EBlock) error {
+
+	res, ok := s.data.chains[chain.ChainID]
+	if !ok {
+		return nil
+	}
+
+	s.deleteEBlocks(chain.ChainID, ancestor.BlockSequenceNumber)
+	res.LatestEntryBlock = ancestor.KeyMR
+
+	return nil
+
+}
+
+func (s *memStore) GetDBlock(dblock *model.DBlock) *model.DBlock {
+
+	res, ok := s.data.dblocks[dblock.Height]
+	if !ok {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+func (s *memStore) GetLatestDBlock() *model.DBlock {
+
+	var res *model.DBlock
+	for _, dblock := range s.data.dblocks {
+		if res == nil || dblock.Height > res.Height {
+			res = dblock
+		}
+	}
+	if res == nil {
+		return nil
+	}
+	c := *res
+	return &c
+
+}
+
+func (s *memStore) SaveDBlock(dblock *model.DBlock) error {
+
+	c := *dblock
+	s.data.dblocks[dblock.Height] = &c
+	return nil
+
+}
+
+func (s *memStore) DeleteDBlocksAfter(height int64) error {
+
+	for h := range s.data.dblocks {
+		if h > height {
+			delete(s.data.dblocks, h)
+		}
+	}
+	return nil
+
+}
+
+func (s *memStore) CreateMissingSyncJobs() error {
+
+	for id, chain := range s.data.chains {
+		if _, ok := s.data.syncJobs[id]; !ok && (chain.Synced == nil || !*chain.Synced) {
+			s.data.syncJobs[id] = &model.SyncJob{ChainID: id, State: model.SyncJobPending}
+		}
+	}
+	return nil
+
+}
+
+func (s *memStore) TransitSyncJob(chainID string, from []string, fields map[string]interface{}) error {
+
+	job, ok := s.data.syncJobs[chainID]
+	if ok {
+		for _, state := range from {
+			if job.State != state {
+				continue
+			}
+			if state, ok := fields["state"].(string); ok {
+				job.State = state
+			}
+			if attempts, ok := fields["attempts"].(int); ok {
+				job.Attempts = attempts
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("DB: Sync job of chain %s not found or its state is not one of %v", chainID, from)
+
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/FactomProject/factom"
+	log "github.com/sirupsen/logrus"
+)
+
+// errChainReorg is returned by parseEntryBlocks, if entry blocks of chain don't reach the latest parsed one
+var errChainReorg = fmt.Errorf("Updates parser: Entry blocks of chain were replaced on Factom")
+
+// parseChainUpdates parses entry blocks of chain from keyMR till chain.LatestEntryBlock & updates chain.LatestEntryBlock.
+// If entry blocks were replaced on Factom, local entry blocks after the common one are rolled back & replaced.
+func (c *Context) parseChainUpdates(chain *model.Chain, keyMR string) error {
+
+	err := c.parseEntryBlocks(keyMR, chain.LatestEntryBlock, false)
+
+	if err == errChainReorg {
+
+		log.Warn("Updates parser: Entry blocks of chain ", chain.ChainID, " were replaced on Factom, rolling back")
+
+		latest := c.store.GetEBlock(&model.EBlock{KeyMR: chain.LatestEntryBlock})
+		if latest == nil {
+			return fmt.Errorf("Updates parser: EntryBlock %s not found into local DB", chain.LatestEntryBlock)
+		}
+
+		ancestor, replacement, err := c.findCommonEBlock(keyMR, latest.BlockSequenceNumber)
+		if err != nil {
+			return err
+		}
+
+		// nothing to keep, so chain is synced from scratch by history fetching
+		if ancestor == nil {
+			log.Info("Updates parser: No common EntryBlock of chain ", chain.ChainID, " found, resetting chain")
+			return resetChainData(c.store, chain, "")
+		}
+
+		// entries of replacement entry blocks are fetched before rollback,
+		// so DB transaction is not kept open while waiting for factomd
+		entries := make([][]*model.Entry, len(replacement))
+		for i, eb := range replacement {
+			entries[i], _, err = c.fetchEntryBlockEntries(eb.eblock, "")
+			if err != nil {
+				return err
+			}
+		}
+
+		// rollback & replacement entry blocks are written in one transaction,
+		// so local data of chain is kept untouched, if writing fails
+		return c.store.WithTx(func(tx store.Store) error {
+
+			err := tx.RollbackChain(chain, ancestor)
+			if err != nil {
+				return err
+			}
+
+			for i := len(replacement) - 1; i >= 0; i-- {
+				err = saveEntryBlock(tx, replacement[i].keyMR, replacement[i].eblock, entries[i], false)
+				if err != nil {
+					return err
+				}
+			}
+
+			log.Info("Updates parser: Chain ", chain.ChainID, " rolled back to EntryBlock ", ancestor.KeyMR)
+
+			return tx.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: keyMR})
+
+		})
+
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: keyMR})
+
+}
+
+// fetchedEBlock is entry block fetched from Factom with its keymr
+type fetchedEBlock struct {
+	keyMR  string
+	eblock *factom.EBlock
+}
+
+// findCommonEBlock walks entry blocks of chain on Factom from keyMR & returns the first one,
+// that is stored into local DB & has sequence number <= maxSequence, or nil if there is no such entry block.
+// Entry blocks walked before the common one are returned too, the latest first.
+func (c *Context) findCommonEBlock(keyMR string, maxSequence int64) (*model.EBlock, []*fetchedEBlock, error) {
+
+	var walked []*fetchedEBlock
+
+	for keyMR != factom.ZeroHash {
+
+		eb, err := factom.GetEBlock(keyMR)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if eb.Header.BlockSequenceNumber <= maxSequence {
+			if local := c.store.GetEBlock(&model.EBlock{KeyMR: keyMR}); local != nil {
+				return local, walked, nil
+			}
+		}
+
+		walked = append(walked, &fetchedEBlock{keyMR: keyMR, eblock: eb})
+		keyMR = eb.Header.PrevKeyMR
+
+	}
+
+	return nil, walked, nil
+
+}
+
+// rollbackDBlocks finds the latest processed directory block (starting from height), that is still on Factom,
+// and rolls back entry blocks of tracked chains & processed directory blocks after it
+func (c *Context) rollbackDBlocks(height int64) (*model.DBlock, error) {
+
+	var common *model.DBlock
+
+	for h := height; h > 0; h-- {
+
+		dblock, err := c.fetchDBlock(h)
+		if err != nil {
+			return nil, err
+		}
+
+		local := c.store.GetDBlock(&model.DBlock{Height: h})
+
+		// directory blocks before the first processed one can't be checked
+		if local == nil || local.KeyMR == dblock.KeyMR {
+			common = dblock
+			break
+		}
+
+	}
+
+	if common == nil {
+		return nil, fmt.Errorf("Updates parser: No common DBlock found on Factom")
+	}
+
+	// chains & processed directory blocks are rolled back in one transaction,
+	// so rollback is retried from scratch on the next iteration, if it fails
+	err := c.store.WithTx(func(tx store.Store) error {
+
+		for _, chain := range tx.GetChainsWithEBlocksAfter(common.Height) {
+
+			ancestor := tx.GetChainLatestEBlock(chain, common.Height)
+
+			// nothing to keep, so chain is synced from scratch by history fetching
+			if ancestor == nil {
+				if err := resetChainData(tx, chain, ""); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := tx.RollbackChain(chain, ancestor); err != nil {
+				return err
+			}
+
+		}
+
+		if err := tx.DeleteDBlocksAfter(common.Height); err != nil {
+			return err
+		}
+
+		return tx.SaveDBlock(common)
+
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Updates parser: Rolled back to DBlock ", common.Height)
+
+	return common, nil
+
+}
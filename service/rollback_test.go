@@ -0,0 +1,264 @@
+package service
+
+import (
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	"testing"
+)
+
+// storeTestEBlocks stores entry blocks from keyMR till the first one of chain & sets chain.LatestEntryBlock to keyMR
+func storeTestEBlocks(t *testing.T, c *Context, chainID string, keyMR string) {
+
+	if err := c.parseEntryBlocks(keyMR, factom.ZeroHash, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.store.UpdateChain(&model.Chain{ChainID: chainID, LatestEntryBlock: keyMR}); err != nil {
+		t.Fatal(err)
+	}
+
+}
+
+// entryHashes returns hashes of entries of entry blocks on fake factomd
+func (f *fakeFactomd) entryHashes(keyMRs ...string) []string {
+
+	var res []string
+	for _, keyMR := range keyMRs {
+		for _, item := range f.eblocks[keyMR].EntryList {
+			res = append(res, item.EntryHash)
+		}
+	}
+	return res
+
+}
+
+func TestParseNewDBlocksRollsBackReplacedDBlocks(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	chainID, first := newTestChain("dblocks")
+
+	e0 := f.addEBlock(chainID, factom.ZeroHash, 9, first, 1)
+	f.addDBlock(9, e0)
+
+	s := newMemStore()
+	s.CreateChain(&model.Chain{ChainID: chainID, Status: model.ChainCompleted})
+	s.SaveDBlock(&model.DBlock{Height: 9, KeyMR: f.dblocks[9].KeyMR})
+
+	c := &Context{store: s}
+
+	storeTestEBlocks(t, c, chainID, e0)
+
+	e1 := f.addEBlock(chainID, e0, 10, nil, 2)
+	f.addDBlock(10, e1)
+	e2 := f.addEBlock(chainID, e1, 11, nil, 2)
+	f.addDBlock(11, e2)
+
+	if err := c.ParseNewDBlocks(11); err != nil {
+		t.Fatal(err)
+	}
+
+	if latest := s.GetChain(&model.Chain{ChainID: chainID}).LatestEntryBlock; latest != e2 {
+		t.Fatalf("LatestEntryBlock is %s, expected %s", latest, e2)
+	}
+
+	// directory blocks 10 & 11 are replaced on Factom
+	r1 := f.addEBlock(chainID, e0, 10, nil, 1)
+	f.addDBlock(10, r1)
+	r2 := f.addEBlock(chainID, r1, 11, nil, 1)
+	f.addDBlock(11, r2)
+	r3 := f.addEBlock(chainID, r2, 12, nil, 1)
+	f.addDBlock(12, r3)
+
+	if err := c.ParseNewDBlocks(12); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, keyMR := range []string{e1, e2} {
+		if s.GetEBlock(&model.EBlock{KeyMR: keyMR}) != nil {
+			t.Errorf("replaced EntryBlock %s is not rolled back", keyMR)
+		}
+	}
+
+	for _, hash := range f.entryHashes(e1, e2) {
+		if _, ok := s.data.entries[hash]; ok {
+			t.Errorf("entry %s of replaced EntryBlock is not rolled back", hash)
+		}
+	}
+
+	for _, keyMR := range []string{e0, r1, r2, r3} {
+		if s.GetEBlock(&model.EBlock{KeyMR: keyMR}) == nil {
+			t.Errorf("EntryBlock %s is not stored", keyMR)
+		}
+	}
+
+	for _, hash := range f.entryHashes(e0, r1, r2, r3) {
+		if _, ok := s.data.entries[hash]; !ok {
+			t.Errorf("entry %s is not stored", hash)
+		}
+	}
+
+	chain := s.GetChain(&model.Chain{ChainID: chainID})
+
+	if chain.LatestEntryBlock != r3 {
+		t.Errorf("LatestEntryBlock is %s, expected %s", chain.LatestEntryBlock, r3)
+	}
+
+	if chain.Synced == nil || !*chain.Synced {
+		t.Error("chain is not synced after rollback")
+	}
+
+	for h := int64(9); h <= 12; h++ {
+		local := s.GetDBlock(&model.DBlock{Height: h})
+		if local == nil || local.KeyMR != f.dblocks[h].KeyMR {
+			t.Errorf("DBlock %d is %v, expected %s", h, local, f.dblocks[h].KeyMR)
+		}
+	}
+
+}
+
+func TestParseChainUpdatesKeepsChainOnFailedRollback(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	chainID, first := newTestChain("eblocks")
+
+	e0 := f.addEBlock(chainID, factom.ZeroHash, 9, first, 1)
+	e1 := f.addEBlock(chainID, e0, 10, nil, 1)
+
+	s := newMemStore()
+	s.CreateChain(&model.Chain{ChainID: chainID, Status: model.ChainCompleted})
+
+	c := &Context{store: s}
+
+	storeTestEBlocks(t, c, chainID, e1)
+
+	// e1 is replaced on Factom
+	r1 := f.addEBlock(chainID, e0, 10, nil, 1)
+	r2 := f.addEBlock(chainID, r1, 11, nil, 1)
+
+	// r2 is stored before replacement is found, r1 & r2 are stored again after rollback, r2 fails
+	s.hooks.entryCalls = 0
+	s.hooks.failEntryAt = 3
+
+	if err := c.parseChainUpdates(s.GetChain(&model.Chain{ChainID: chainID}), r2); err == nil {
+		t.Fatal("expected error of the failed entry insert")
+	}
+
+	if latest := s.GetChain(&model.Chain{ChainID: chainID}).LatestEntryBlock; latest != e1 {
+		t.Errorf("LatestEntryBlock is %s, expected %s", latest, e1)
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: e1}) == nil {
+		t.Error("rollback is not undone after failure")
+	}
+
+	for _, hash := range f.entryHashes(e1) {
+		if _, ok := s.data.entries[hash]; !ok {
+			t.Errorf("entry %s is deleted, though rollback failed", hash)
+		}
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: r1}) != nil {
+		t.Error("EntryBlock of failed rollback is stored")
+	}
+
+	s.hooks.failEntryAt = 0
+
+	if err := c.parseChainUpdates(s.GetChain(&model.Chain{ChainID: chainID}), r2); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: e1}) != nil {
+		t.Error("replaced EntryBlock is not rolled back")
+	}
+
+	for _, hash := range f.entryHashes(e1) {
+		if _, ok := s.data.entries[hash]; ok {
+			t.Errorf("entry %s of replaced EntryBlock is not rolled back", hash)
+		}
+	}
+
+	for _, keyMR := range []string{e0, r1, r2} {
+		if s.GetEBlock(&model.EBlock{KeyMR: keyMR}) == nil {
+			t.Errorf("EntryBlock %s is not stored", keyMR)
+		}
+	}
+
+	if latest := s.GetChain(&model.Chain{ChainID: chainID}).LatestEntryBlock; latest != r2 {
+		t.Errorf("LatestEntryBlock is %s, expected %s", latest, r2)
+	}
+
+}
+
+func TestRollbackDBlocksResetsChainHeldByWorker(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	chainID, first := newTestChain("worker")
+
+	f.addDBlock(9)
+	e0 := f.addEBlock(chainID, factom.ZeroHash, 10, first, 1)
+	f.addDBlock(10, e0)
+	e1 := f.addEBlock(chainID, e0, 11, nil, 1)
+	f.addDBlock(11, e1)
+
+	s := newMemStore()
+	s.CreateChain(&model.Chain{ChainID: chainID, Status: model.ChainCompleted, LatestEntryBlock: e1, WorkerID: 1})
+	s.data.syncJobs[chainID] = &model.SyncJob{ChainID: chainID, State: model.SyncJobRunning, Attempts: 1}
+	for h := int64(9); h <= 11; h++ {
+		s.SaveDBlock(&model.DBlock{Height: h, KeyMR: f.dblocks[h].KeyMR})
+	}
+
+	c := &Context{store: s}
+
+	// worker has fetched the latest entry block of chain
+	if _, err := c.storeEntryBlock(e1, f.eblocks[e1], true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// the whole chain is replaced on Factom
+	r0 := f.addEBlock(chainID, factom.ZeroHash, 10, first, 2)
+	f.addDBlock(10, r0)
+	f.addDBlock(11)
+	f.addDBlock(12)
+
+	if err := c.ParseNewDBlocks(12); err != nil {
+		t.Fatal(err)
+	}
+
+	chain := s.GetChain(&model.Chain{ChainID: chainID})
+
+	if s.GetEBlock(&model.EBlock{KeyMR: e1}) != nil {
+		t.Error("replaced EntryBlock is not deleted")
+	}
+
+	if chain.EarliestEntryBlock != "" || chain.LatestEntryBlock != "" {
+		t.Errorf("EarliestEntryBlock=%s, LatestEntryBlock=%s, expected empty", chain.EarliestEntryBlock, chain.LatestEntryBlock)
+	}
+
+	if chain.WorkerID != -1 {
+		t.Errorf("WorkerID is %d, expected -1", chain.WorkerID)
+	}
+
+	if job := s.GetSyncJob(&model.SyncJob{ChainID: chainID}); job.State != model.SyncJobPending || job.Attempts != 0 {
+		t.Errorf("sync job is %s with %d attempts, expected pending with 0 attempts", job.State, job.Attempts)
+	}
+
+	// worker stops before storing the next entry block of replaced chain
+	if _, err := c.storeEntryBlock(e0, f.eblocks[e0], true, ""); err != errSyncJobStopped {
+		t.Fatalf("expected errSyncJobStopped, got %v", err)
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: e0}) != nil {
+		t.Error("EntryBlock of replaced chain is stored by worker")
+	}
+
+	if chain := s.GetChain(&model.Chain{ChainID: chainID}); chain.EarliestEntryBlock != "" {
+		t.Errorf("EarliestEntryBlock is set to %s by worker", chain.EarliestEntryBlock)
+	}
+
+}
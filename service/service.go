@@ -1,57 +1,104 @@
 package service
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/config"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
 	"github.com/DeFacto-Team/Factom-Open-API/store"
 	"github.com/DeFacto-Team/Factom-Open-API/wallet"
 	"github.com/FactomProject/factom"
 	"github.com/jinzhu/copier"
+	"github.com/jinzhu/gorm"
 	log "github.com/sirupsen/logrus"
+	"io"
+	"sort"
 	"time"
 )
 
+const (
+	// max length of NDJSON line while importing chain (entry is max 10KB, but base64 encoded)
+	ImportMaxLineSize = 1024 * 1024
+	// max number of requests (or batches of requests) to factomd sent concurrently while parsing entry block
+	EntryFetchConcurrency = 10
+)
+
 // Service is an interface with all core functions
 type Service interface {
 	CreateUser(user *model.User) error
 	CheckUser(token string) *model.User
 	UpdateUser(user *model.User) error
+	GetUserECBalance(user *model.User) (int64, error)
 
 	GetChain(chain *model.Chain, user *model.User) (*model.Chain, error)
 	GetChains(chain *model.Chain) []*model.Chain
+	GetChainSyncStatus(chain *model.Chain) *model.Chain
+	LookupChain(chain *model.Chain) (*model.Chain, error)
+	UntrackChain(chain *model.Chain, user *model.User) error
+	CollectUntrackedChains(gracePeriod time.Duration) (int, error)
+	GetSyncingChains() []*model.Chain
+	EstimateChainSyncSize(chain *model.Chain) int64
+	SetChainSyncPriority(chain *model.Chain, priority int) (*model.Chain, error)
+	PauseChainSync(chain *model.Chain) (*model.Chain, error)
+	ResumeChainSync(chain *model.Chain) (*model.Chain, error)
+	CancelChainSync(chain *model.Chain) (*model.Chain, error)
+	ResyncChain(chain *model.Chain) (*model.Chain, error)
+	GetChainsToSync() []*model.Chain
+	RunSyncJob(chain *model.Chain, workerID int) error
 	GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	SearchUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	SetChainSentToPool(chain *model.Chain) error
 	ResetChainParsing(chain *model.Chain) error
 	ResetChainsParsingAtAPIStart() error
 	CreateChain(chain *model.Chain, user *model.User) (*model.Chain, error)
+	ValidateChain(chain *model.Chain) (*model.Estimate, error)
 	GetChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error)
 	SearchChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error)
 	GetChainFirstOrLastEntry(entry *model.Entry, sort string, user *model.User) (*model.Entry, error)
+	ExportChainEntries(chain *model.Chain, fn func(*model.ExportEntry) error) error
+	ImportChain(chain *model.Chain, archive io.Reader) (*model.ImportResult, error)
+	VerifyChain(chain *model.Chain, repair bool) (*model.VerifyReport, error)
 
 	GetEntry(entry *model.Entry, user *model.User) (*model.Entry, error)
+	LookupEntry(entry *model.Entry) (*model.Entry, error)
 	CreateEntry(entry *model.Entry, user *model.User) (*model.Entry, error)
+	ValidateEntry(entry *model.Entry) (*model.Estimate, error)
+	GetEntryReceipt(entry *model.Entry, user *model.User) (*model.Receipt, error)
+	EstimateWrites(entries []*model.Entry, user *model.User) *model.EstimateResult
 
 	GetQueue(queue *model.Queue) []*model.Queue
+	GetWalletStatus() *model.WalletStatus
+	MonitorBalances() error
+	GetECPurchases() []*model.ECPurchase
 	GetQueueToProcess() []*model.Queue
-	GetQueueToClear() []*model.Queue
+	GetQueueToAck() []*model.Queue
 	ProcessQueue(queue *model.Queue) error
-	ClearQueue(queue *model.Queue) error
+	TrackQueueAck(queue *model.Queue) error
 
 	ParseAllChainEntries(chain *model.Chain, workerID int) error
 	ParseNewChainEntries(chain *model.Chain) error
+	ParseNewDBlocks(height int64) error
+	GetLatestDBlock() *model.DBlock
+	GetUpdatesStatus() (*model.UpdatesStatus, error)
 }
 
-// NewService initializes service with store & wallet as ServiceContext
-func NewService(store store.Store, wallet wallet.Wallet) Service {
-	return &Context{store: store, wallet: wallet}
+// NewService initializes service with config, store & wallet as ServiceContext
+func NewService(conf *config.Config, store store.Store, wallet wallet.Wallet) Service {
+	return &Context{conf: conf, store: store, wallet: wallet, alerts: make(map[string]bool)}
 }
 
-// Context keeps store & wallet instances
+// Context keeps config, store & wallet instances
 type Context struct {
+	conf   *config.Config
 	store  store.Store
 	wallet wallet.Wallet
+	// set to 1 if factomd node rejected JSON-RPC batch request
+	batchUnsupported int32
+	factomd          factomdClient
+	// wallet alerts, that are currently fired, by key
+	alerts map[string]bool
 }
 
 // CreateUser is generic function to create user into DB
@@ -81,6 +128,35 @@ func (c *Context) UpdateUser(user *model.User) error {
 	return nil
 }
 
+// GetUserECBalance is high-level function, that run by api.getUser()
+// Returns balance of user's own EC address
+func (c *Context) GetUserECBalance(user *model.User) (int64, error) {
+
+	if user.ECAddress == "" {
+		return 0, fmt.Errorf("User %s has no EC address", user.Name)
+	}
+
+	return factom.GetECBalance(user.ECAddress)
+
+}
+
+// getUserEC returns decrypted own EC address of user or nil, if user has no Es address
+func (c *Context) getUserEC(user *model.User) (*factom.ECAddress, error) {
+
+	// zero ID is ignored by GetUser() & would match any user
+	if user.ID == 0 {
+		return nil, nil
+	}
+
+	localUser := c.store.GetUser(user)
+	if localUser == nil || localUser.EsAddress == "" {
+		return nil, nil
+	}
+
+	return wallet.DecryptEsAddress(c.conf, localUser.EsAddress)
+
+}
+
 // GetChain is high-level function, that run by api.GetChain()
 func (c *Context) GetChain(chain *model.Chain, user *model.User) (*model.Chain, error) {
 
@@ -98,6 +174,8 @@ func (c *Context) GetChain(chain *model.Chain, user *model.User) (*model.Chain,
 			log.Error(err)
 		}
 
+		c.fillSyncProgress(localChain)
+
 		// localChain already base64 encoded
 		return localChain, nil
 	}
@@ -160,6 +238,179 @@ func (c *Context) SetChainSentToPool(chain *model.Chain) error {
 
 }
 
+// LookupChain is high-level function, that run by api.GetChain() with track=false
+// Chain is returned from local DB or from Factom, but it's not stored into local DB & not bound to user, so its history is not synced
+func (c *Context) LookupChain(chain *model.Chain) (*model.Chain, error) {
+
+	if localChain := c.GetChainSyncStatus(chain); localChain != nil {
+		return localChain, nil
+	}
+
+	if chain.Exists() {
+		chain = chain.Base64Encode()
+		chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+		return chain, nil
+	}
+
+	return nil, fmt.Errorf("Chain %s does not exist", chain.ChainID)
+
+}
+
+// UntrackChain is high-level function, that run by api.untrackChain()
+// It removes binding of chain to user. Chains, that are not bound to any user, are deleted by CollectUntrackedChains().
+func (c *Context) UntrackChain(chain *model.Chain, user *model.User) error {
+
+	if c.store.GetChain(&model.Chain{ChainID: chain.ChainID}) == nil {
+		return fmt.Errorf("Chain %s not found into local DB", chain.ChainID)
+	}
+
+	log.Debug("Unbinding chain ", chain.ChainID, " from user ", user.Name)
+
+	return c.store.UnbindChainFromUser(chain, user)
+
+}
+
+// CollectUntrackedChains deletes chains, that are not bound to any user longer than gracePeriod, from local DB
+func (c *Context) CollectUntrackedChains(gracePeriod time.Duration) (int, error) {
+
+	before := time.Now().Add(-gracePeriod)
+
+	var count int
+
+	for _, chain := range c.store.GetUntrackedChains(before) {
+		deleted, err := c.store.DeleteUntrackedChain(chain, before)
+		if err != nil {
+			return count, err
+		}
+		if !deleted {
+			log.Debug("GC: Chain ", chain.ChainID, " is in use, skipping")
+			continue
+		}
+		log.Info("GC: Deleted untracked chain ", chain.ChainID)
+		count++
+	}
+
+	return count, nil
+
+}
+
+// GetChainSyncStatus returns local chain with sync progress, or nil if chain not found into local DB
+func (c *Context) GetChainSyncStatus(chain *model.Chain) *model.Chain {
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+	if localChain == nil {
+		return nil
+	}
+
+	c.fillSyncProgress(localChain)
+
+	return localChain
+
+}
+
+// GetSyncingChains is high-level function, that run by api.getSyncingChains()
+func (c *Context) GetSyncingChains() []*model.Chain {
+
+	chains := c.store.GetChainsBySyncJobState(model.SyncJobPending, model.SyncJobRunning)
+
+	for _, chain := range chains {
+		c.fillSyncProgress(chain)
+	}
+
+	return chains
+
+}
+
+// EstimateChainSyncSize returns number of entry blocks of chain, that are not fetched yet, or -1 if unknown.
+// If no entry blocks of chain fetched, chainhead is requested from Factom once & cached into sync job.
+func (c *Context) EstimateChainSyncSize(chain *model.Chain) int64 {
+
+	c.fillSyncProgress(chain)
+
+	if chain.SyncProgress == nil {
+		return 0
+	}
+
+	if remaining := chain.SyncProgress.Remaining(); remaining >= 0 || chain.LatestEntryBlock == "" {
+		return remaining
+	}
+
+	job := chain.SyncProgress.Job
+	if job != nil && job.SizeEstimate >= 0 {
+		return job.SizeEstimate
+	}
+
+	eb, err := factom.GetEBlock(chain.LatestEntryBlock)
+	if err != nil {
+		return -1
+	}
+
+	size := eb.Header.BlockSequenceNumber + 1
+
+	if job != nil {
+		err = c.store.TransitSyncJob(chain.ChainID, []string{job.State}, map[string]interface{}{"size_estimate": size})
+		if err != nil {
+			log.Debug(err)
+		}
+	}
+
+	return size
+
+}
+
+// SetChainSyncPriority is high-level function, that run by api.bumpChainSync()
+func (c *Context) SetChainSyncPriority(chain *model.Chain, priority int) (*model.Chain, error) {
+
+	err := c.store.SetChainSyncPriority(chain, priority)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetChainSyncStatus(chain), nil
+
+}
+
+// requestChainSync marks chain as requested by user, so it's synced before chains nobody is waiting for.
+// Chain is updated only once, by the first user requested it, not on every request while it's syncing.
+func (c *Context) requestChainSync(chain *model.Chain, user *model.User) {
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+	if localChain == nil || localChain.SyncRequestedBy != 0 {
+		return
+	}
+
+	err := c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, SyncRequestedBy: user.ID})
+	if err != nil {
+		log.Error(err)
+	}
+
+}
+
+// fillSyncProgress sets chain.SyncProgress for chains, that are not synced yet
+func (c *Context) fillSyncProgress(chain *model.Chain) {
+
+	if chain.Synced != nil && *chain.Synced {
+		return
+	}
+
+	var earliest, latest *model.EBlock
+
+	if chain.EarliestEntryBlock != "" {
+		earliest = c.store.GetEBlock(&model.EBlock{KeyMR: chain.EarliestEntryBlock})
+	}
+
+	if chain.LatestEntryBlock != "" {
+		latest = c.store.GetEBlock(&model.EBlock{KeyMR: chain.LatestEntryBlock})
+	}
+
+	eblocks, entries := c.store.CountChainEBlocksAndEntries(chain)
+
+	job := c.store.GetSyncJob(&model.SyncJob{ChainID: chain.ChainID})
+
+	chain.SyncProgress = model.NewSyncProgress(chain, job, earliest, latest, eblocks, entries)
+
+}
+
 // ResetChainParsing resets WorkerID & SentToPool params of the chain. A fallback function, that runs in case of error while chain syncing.
 func (c *Context) ResetChainParsing(chain *model.Chain) error {
 
@@ -171,6 +422,12 @@ func (c *Context) ResetChainParsing(chain *model.Chain) error {
 // ResetChainsParsingAtAPIStart resets WorkerID & SentToPool params of unsynced chains on API start, to let them finish syncing
 func (c *Context) ResetChainsParsingAtAPIStart() error {
 
+	// jobs interrupted by API restart are not failed
+	err := c.store.TransitSyncJob("", []string{model.SyncJobRunning}, map[string]interface{}{"state": model.SyncJobPending})
+	if err != nil {
+		return err
+	}
+
 	t := false
 	return c.store.UpdateChainsWhere("synced IS FALSE", &model.Chain{WorkerID: -1, SentToPool: &t})
 
@@ -181,32 +438,14 @@ func (c *Context) CreateChain(chain *model.Chain, user *model.User) (*model.Chai
 
 	chain = chain.Base64Decode()
 
-	log.Debug("Checking if first entry of chain fits into 10KB")
-	_, err := chain.ConvertToEntryModel().Fit10KB()
+	err := c.validateChain(chain)
 	if err != nil {
 		return nil, err
 	}
-	chain.ChainID = chain.ID()
 
 	// default chain status for new chains
 	chain.Status = model.ChainQueue
 
-	// check if chain exists on Factom
-	if chain.Exists() == true {
-		log.Error("Chain " + chain.ChainID + " already exists on Factom")
-		return nil, fmt.Errorf("Chain " + chain.ChainID + " exists")
-	}
-
-	// search for chain.ChainID into local DB
-	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
-
-	if localChain != nil {
-		log.Error("Chain " + chain.ChainID + " already into local DB")
-		return nil, fmt.Errorf("Chain " + chain.ChainID + " exists")
-	}
-
-	log.Debug("Chain ", chain.ChainID, " not found both on Factom & into local DB")
-
 	// new chain & entry into local DB will be created with FactomTime=NOW()
 	timeNow := time.Now().UTC().Round(time.Second)
 	chain.FactomTime = &timeNow
@@ -239,6 +478,51 @@ func (c *Context) CreateChain(chain *model.Chain, user *model.User) (*model.Chai
 
 }
 
+// ValidateChain is high-level function, that run by api.CreateChain() with dryRun=true
+// Chain is validated the same way as by CreateChain(), but nothing is written into local DB or queue
+func (c *Context) ValidateChain(chain *model.Chain) (*model.Estimate, error) {
+
+	chain = chain.Base64Decode()
+
+	err := c.validateChain(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	return estimateWrite(chain.ConvertToEntryModel(), model.QueueActionChain), nil
+
+}
+
+// validateChain checks if base64-decoded chain can be created & fills its ChainID
+func (c *Context) validateChain(chain *model.Chain) error {
+
+	log.Debug("Checking if first entry of chain fits into 10KB")
+	_, err := chain.ConvertToEntryModel().Fit10KB()
+	if err != nil {
+		return err
+	}
+	chain.ChainID = chain.ID()
+
+	// check if chain exists on Factom
+	if chain.Exists() == true {
+		log.Error("Chain " + chain.ChainID + " already exists on Factom")
+		return fmt.Errorf("Chain " + chain.ChainID + " exists")
+	}
+
+	// search for chain.ChainID into local DB
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+
+	if localChain != nil {
+		log.Error("Chain " + chain.ChainID + " already into local DB")
+		return fmt.Errorf("Chain " + chain.ChainID + " exists")
+	}
+
+	log.Debug("Chain ", chain.ChainID, " not found both on Factom & into local DB")
+
+	return nil
+
+}
+
 // GetChainEntries is high-level function, that run by api.GetChainEntries()
 func (c *Context) GetChainEntries(entry *model.Entry, user *model.User, start int, limit int, sort string, force bool) ([]*model.Entry, int, error) {
 
@@ -288,6 +572,7 @@ func (c *Context) GetChainEntries(entry *model.Entry, user *model.User, start in
 	if !force {
 		// check if chain just created or not fully synced yet
 		if flagJustCreated == true || (localChain.Status == model.ChainCompleted && !(*localChain.Synced)) {
+			c.requestChainSync(chain, user)
 			return nil, 0, nil
 		}
 	}
@@ -348,6 +633,7 @@ func (c *Context) SearchChainEntries(entry *model.Entry, user *model.User, start
 	if !force {
 		// check if chain just created or not fully synced yet
 		if flagJustCreated == true || (localChain.Status == model.ChainCompleted && !(*localChain.Synced)) {
+			c.requestChainSync(chain, user)
 			return nil, 0, nil
 		}
 	}
@@ -405,6 +691,7 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 
 	// check if chain just created or not fully synced yet
 	if flagJustCreated == true {
+		c.requestChainSync(chain, user)
 		return nil, nil
 	}
 
@@ -412,10 +699,12 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 	if localChain.Status == model.ChainCompleted && !(*localChain.Synced) {
 		switch sort {
 		case "asc":
+			c.requestChainSync(chain, user)
 			return nil, nil
 		case "desc":
 			// while requesting last entry, it's enough to have at least one entry block parsed to return a result
 			if localChain.EarliestEntryBlock == "" {
+				c.requestChainSync(chain, user)
 				return nil, nil
 			}
 		}
@@ -427,109 +716,555 @@ func (c *Context) GetChainFirstOrLastEntry(entry *model.Entry, sort string, user
 
 }
 
-// GetEntry is high-level function, that run by api.GetEntry()
-func (c *Context) GetEntry(entry *model.Entry, user *model.User) (*model.Entry, error) {
+// ExportChainEntries is high-level function, that run by api.ExportChain()
+// Chain should be already fetched with GetChain(), so only locally stored entries are exported
+func (c *Context) ExportChainEntries(chain *model.Chain, fn func(*model.ExportEntry) error) error {
 
-	log.Debug("Search for entry into local DB")
+	log.Debug("Exporting entries of chain ", chain.ChainID)
 
-	// search for chain.ChainID into local DB
-	localentry := c.store.GetEntry(entry, "")
+	return c.store.ExportChainEntries(chain, fn)
 
-	if localentry != nil {
-		log.Debug("Entry " + entry.EntryHash + " found into local DB")
+}
 
-		log.Debug("Force binding chain ", localentry.ChainID, " to user ", user.Name)
-		err := c.store.BindChainToUser(localentry.GetChain(), user)
-		if err != nil {
-			log.Error(err)
+// ImportChain bootstraps local chain from NDJSON archive, made by ExportChainEntries() on another Open API instance.
+// Every entry hash and linkage of entry blocks are verified before writing anything into local DB,
+// and every entry block of archive is checked against the one fetched from Factom.
+// Chain is marked as synced only if archive reaches the first entry block of the chain.
+func (c *Context) ImportChain(chain *model.Chain, archive io.Reader) (*model.ImportResult, error) {
+
+	log.Debug("Import: Checking chain " + chain.ChainID)
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+
+	// import is possible only into chains without locally parsed entry blocks
+	if localChain != nil {
+		if localChain.EarliestEntryBlock != "" || (localChain.Synced != nil && *localChain.Synced) {
+			return nil, fmt.Errorf("Import: Chain %s already has locally synced entry blocks", chain.ChainID)
+		}
+		if localChain.WorkerID > 0 {
+			return nil, fmt.Errorf("Import: Chain %s is being synced by worker %d", chain.ChainID, localChain.WorkerID)
 		}
-		// localentry already base64 encoded
-		return localentry, nil
 	}
 
-	log.Debug("Entry " + entry.EntryHash + " not found into local DB")
-	log.Debug("Search for entry on the blockchain")
+	eblocks, entries, err := readChainArchive(chain.ChainID, archive)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := entry.FillModelFromFactom()
+	if len(eblocks) == 0 {
+		return nil, fmt.Errorf("Import: Archive does not contain entry blocks of chain %s", chain.ChainID)
+	}
 
-	if err == nil {
-		log.Debug("Entry " + entry.EntryHash + " found on Factom")
-		resp.Status = resp.GetStatusFromFactom()
+	// sort entry blocks from the latest to the earliest & verify that every entry block refers to the next one
+	sort.Slice(eblocks, func(i, j int) bool {
+		return eblocks[i].BlockSequenceNumber > eblocks[j].BlockSequenceNumber
+	})
 
-		// search for chain.ChainID into local DB
-		localChain := c.store.GetChain(resp.GetChain())
+	for i := 0; i < len(eblocks)-1; i++ {
+		if eblocks[i].PrevKeyMR != eblocks[i+1].KeyMR || eblocks[i].BlockSequenceNumber != eblocks[i+1].BlockSequenceNumber+1 {
+			return nil, fmt.Errorf("Import: EntryBlock %s (#%d) is not linked to EntryBlock %s (#%d)",
+				eblocks[i].KeyMR, eblocks[i].BlockSequenceNumber, eblocks[i+1].KeyMR, eblocks[i+1].BlockSequenceNumber)
+		}
+	}
 
-		if localChain == nil {
-			log.Debug("Chain " + resp.ChainID + " not found into local DB")
-			log.Debug("Creating chain into local DB")
+	latest := eblocks[0]
+	earliest := eblocks[len(eblocks)-1]
 
-			chain := resp.GetChain()
-			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+	reachesFirst := earliest.PrevKeyMR == factom.ZeroHash
+	if reachesFirst != (earliest.BlockSequenceNumber == 0) {
+		return nil, fmt.Errorf("Import: EntryBlock %s has invalid sequence number %d", earliest.KeyMR, earliest.BlockSequenceNumber)
+	}
 
-			// here we add existing Factom chain into local DB with factomTime = null
-			err = c.store.CreateChain(chain)
-			if err != nil {
-				log.Error(err)
-			}
+	// archive headers are trusted only after they are checked against Factom
+	err = c.verifyArchiveEBlocks(eblocks, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	latest = eblocks[0]
+	earliest = eblocks[len(eblocks)-1]
 
+	var first *model.Entry
+	if reachesFirst {
+		first, err = firstEntryOfChain(earliest, entries[earliest.KeyMR])
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		log.Debug("Creating entry into local DB")
+	log.Info("Import: Archive of chain ", chain.ChainID, " verified, EntryBlocks #", earliest.BlockSequenceNumber, "-#", latest.BlockSequenceNumber)
 
-		// get entry timestamp from Factom ONLY IF ENTRY STATUS IS COMPLETED
-		if resp.Status == model.EntryCompleted {
-			factomTime, err := resp.GetTimeFromFactom()
-			if err != nil {
-				log.Error(err)
-			} else {
-				t := time.Unix(factomTime, 0).UTC()
-				resp.FactomTime = &t
-			}
-		}
+	// chain is marked as sent to pool while importing, so history parser won't touch it
+	t := true
+	if localChain == nil {
+		err = c.store.CreateChain(&model.Chain{ChainID: chain.ChainID, Status: model.ChainCompleted, SentToPool: &t})
+	} else {
+		err = c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, SentToPool: &t})
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		err = c.store.CreateEntry(resp.Base64Encode())
-		if err != nil {
-			log.Error(err)
-		}
+	result := &model.ImportResult{ChainID: chain.ChainID, Synced: reachesFirst}
 
-		log.Debug("Force binding chain ", resp.ChainID, " to user ", user.Name)
-		err = c.store.BindChainToUser(resp.GetChain(), user)
+	for _, eblock := range eblocks {
+		err = c.importEntryBlock(eblock, entries[eblock.KeyMR])
 		if err != nil {
-			log.Error(err)
+			c.ResetChainParsing(chain)
+			return nil, err
 		}
-
-		return resp.Base64Encode(), nil
+		result.EBlocks++
+		result.Entries += len(entries[eblock.KeyMR])
 	}
 
-	return nil, fmt.Errorf("Entry %s does not exist", entry.EntryHash)
+	f := false
+	update := &model.Chain{ChainID: chain.ChainID, Status: model.ChainCompleted, EarliestEntryBlock: earliest.KeyMR, LatestEntryBlock: latest.KeyMR, SentToPool: &f}
 
-}
+	// the same as parseEntryBlock() does for the first entry block of chain
+	if reachesFirst {
+		factomTime := time.Unix(earliest.Timestamp, 0).UTC()
+		update.Synced = &t
+		update.FactomTime = &factomTime
+		update.ExtIDs = first.ExtIDs
+		update.WorkerID = -2
+	}
 
-// CreateEntry is high-level function, that run by api.CreateEntry()
-func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entry, error) {
+	err = c.store.UpdateChain(update)
+	if err != nil {
+		return nil, err
+	}
 
-	entry = entry.Base64Decode()
+	log.Info("Import: Chain ", chain.ChainID, " imported, eblocks=", result.EBlocks, ", entries=", result.Entries, ", synced=", result.Synced)
 
-	log.Debug("Checking if entry fits into 10KB")
-	_, err := entry.Fit10KB()
+	return result, nil
+
+}
+
+// readChainArchive reads NDJSON archive line by line & verifies that all entries belong to chainID and have valid hashes.
+// Returns all entry blocks of archive and base64 encoded entries grouped by entry blocks.
+func readChainArchive(chainID string, archive io.Reader) ([]*model.EBlock, map[string][]*model.Entry, error) {
+
+	var eblocks []*model.EBlock
+	headers := make(map[string]*model.EBlock)
+	entries := make(map[string][]*model.Entry)
+
+	scanner := bufio.NewScanner(archive)
+	scanner.Buffer(make([]byte, bufio.MaxScanTokenSize), ImportMaxLineSize)
+
+	for line := 1; scanner.Scan(); line++ {
+
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		item := &model.ExportEntry{}
+		if err := json.Unmarshal(scanner.Bytes(), item); err != nil {
+			return nil, nil, fmt.Errorf("Import: Line %d: %s", line, err.Error())
+		}
+
+		// entries, that were not written on the blockchain yet, are skipped
+		if item.EBlock == nil {
+			log.Debug("Import: Line ", line, ": entry ", item.EntryHash, " is not bound to any EntryBlock, skipping")
+			continue
+		}
+
+		if item.ChainID != chainID || item.EBlock.ChainID != chainID {
+			return nil, nil, fmt.Errorf("Import: Line %d: entry %s does not belong to chain %s", line, item.EntryHash, chainID)
+		}
+
+		entry := &model.Entry{ChainID: chainID, ExtIDs: item.ExtIDs, Content: item.Content, FactomTime: item.FactomTime}
+		entry.EntryHash = entry.Base64Decode().Hash()
+
+		if entry.EntryHash != item.EntryHash {
+			return nil, nil, fmt.Errorf("Import: Line %d: invalid entry hash %s, expected %s", line, item.EntryHash, entry.EntryHash)
+		}
+
+		// all lines of the same entry block should have the same header
+		if header, ok := headers[item.EBlock.KeyMR]; !ok {
+			headers[item.EBlock.KeyMR] = item.EBlock
+			eblocks = append(eblocks, item.EBlock)
+		} else if !header.HeaderEquals(item.EBlock) {
+			return nil, nil, fmt.Errorf("Import: Line %d: inconsistent header of EntryBlock %s", line, item.EBlock.KeyMR)
+		}
+		entries[item.EBlock.KeyMR] = append(entries[item.EBlock.KeyMR], entry)
+
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return eblocks, entries, nil
+
+}
+
+// verifyArchiveEBlocks fetches entry blocks of archive from factomd by their keymrs and checks archive against them:
+// headers should be the same & archive should contain all entries of entry list of every entry block and nothing else.
+// Archive entry blocks are replaced with fetched ones (with entry list), so they are stored the same way as parsed ones,
+// entries are ordered by entry list of their entry block & get time from it.
+func (c *Context) verifyArchiveEBlocks(eblocks []*model.EBlock, entries map[string][]*model.Entry) error {
+
+	log.Debug("Import: Fetching ", len(eblocks), " EntryBlocks of archive from Factom")
+
+	var reqs []*factom.JSON2Request
+
+	for i, eblock := range eblocks {
+		reqs = append(reqs, factom.NewJSON2Request("entry-block", i, map[string]string{"keymr": eblock.KeyMR}))
+	}
+
+	resps, err := c.factomdRequests(reqs)
 	if err != nil {
-		log.Error(err)
-		return nil, fmt.Errorf(err.Error())
+		return err
 	}
 
-	entry.EntryHash = entry.Hash()
+	for i, eblock := range eblocks {
+
+		if resps[i].Error != nil {
+			return fmt.Errorf("Import: EntryBlock %s not found on Factom: %s", eblock.KeyMR, resps[i].Error.Message)
+		}
+
+		eb := new(factom.EBlock)
+		if err := json.Unmarshal(resps[i].JSONResult(), eb); err != nil {
+			return err
+		}
+
+		fetched := model.NewEBlockFromFactomModel(eblock.KeyMR, eb)
+		if !fetched.HeaderEquals(eblock) {
+			return fmt.Errorf("Import: Header of EntryBlock %s does not match Factom", eblock.KeyMR)
+		}
+
+		ordered, err := orderByEntryList(eb, eblock.KeyMR, entries[eblock.KeyMR])
+		if err != nil {
+			return err
+		}
+
+		eblocks[i] = fetched
+		entries[eblock.KeyMR] = ordered
+
+	}
 
-	localChain := c.store.GetChain(entry.GetChain())
+	return nil
+
+}
+
+// orderByEntryList returns entries of archive in the order of entry list of entry block, fetched from factomd
+func orderByEntryList(eb *factom.EBlock, keyMR string, entries []*model.Entry) ([]*model.Entry, error) {
+
+	byHash := make(map[string]*model.Entry)
+	for _, entry := range entries {
+		byHash[entry.EntryHash] = entry
+	}
+
+	listed := make(map[string]bool)
+	res := make([]*model.Entry, len(eb.EntryList))
+
+	for i, listItem := range eb.EntryList {
+		entry, ok := byHash[listItem.EntryHash]
+		if !ok {
+			return nil, fmt.Errorf("Import: Archive does not contain entry %s of EntryBlock %s", listItem.EntryHash, keyMR)
+		}
+		t := time.Unix(listItem.Timestamp, 0).UTC()
+		entry.FactomTime = &t
+		res[i] = entry
+		listed[listItem.EntryHash] = true
+	}
+
+	for hash := range byHash {
+		if !listed[hash] {
+			return nil, fmt.Errorf("Import: Entry %s is not listed in EntryBlock %s on Factom", hash, keyMR)
+		}
+	}
+
+	return res, nil
+
+}
+
+// firstEntryOfChain returns the first entry of chain from entries of the first entry block, ordered by its entry list,
+// and verifies chain ID to be the hash of ExtIDs of the first entry.
+func firstEntryOfChain(eblock *model.EBlock, entries []*model.Entry) (*model.Entry, error) {
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("Import: EntryBlock %s has no entries", eblock.KeyMR)
+	}
+
+	first := entries[0]
+
+	chain := &model.Chain{ExtIDs: first.Base64Decode().ExtIDs}
+	if chain.ID() != eblock.ChainID {
+		return nil, fmt.Errorf("Import: ExtIDs of the first entry %s do not match chain %s", first.EntryHash, eblock.ChainID)
+	}
+
+	return first, nil
+
+}
+
+// importEntryBlock writes verified entry block and its base64 encoded entries into local DB
+func (c *Context) importEntryBlock(eblock *model.EBlock, entries []*model.Entry) error {
+
+	log.Debug("Import: Writing EntryBlock " + eblock.KeyMR)
+
+	for _, entry := range entries {
+		entry.Status = model.EntryCompleted
+	}
+
+	return c.store.CreateEBlockWithEntries(eblock, entries)
+
+}
+
+// VerifyChain checks integrity of local chain data without trusting it.
+// It walks locally stored entry blocks of chain from the latest to the earliest one via PrevKeyMR,
+// recomputes hashes of their entries and checks that entry list of every entry block is fully present into local DB.
+// If repair is true, missing & corrupted entry blocks and entries are re-fetched from Factom.
+func (c *Context) VerifyChain(chain *model.Chain, repair bool) (*model.VerifyReport, error) {
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
 	if localChain == nil {
+		return nil, fmt.Errorf("Chain %s not found into local DB", chain.ChainID)
+	}
+
+	report := &model.VerifyReport{ChainID: chain.ChainID}
+
+	synced := localChain.Synced != nil && *localChain.Synced
+
+	// nothing parsed yet
+	if localChain.EarliestEntryBlock == "" && !synced {
+		return report, nil
+	}
+
+	log.Info("Verify: Checking chain ", chain.ChainID, ", repair=", repair)
+
+	var next *model.EBlock
+
+	for keyMR := localChain.LatestEntryBlock; keyMR != "" && keyMR != factom.ZeroHash; {
+
+		eblock := c.verifyEntryBlock(keyMR, chain.ChainID, next, report, repair)
+		if eblock == nil {
+			// chain can not be walked further without entry block
+			return report, nil
+		}
+
+		c.verifyEntryBlockEntries(eblock, report, repair)
+		report.EBlocks++
+
+		// unsynced chains are checked only till the earliest parsed entry block
+		if !synced && keyMR == localChain.EarliestEntryBlock {
+			return report, nil
+		}
+
+		next = eblock
+		keyMR = eblock.PrevKeyMR
+	}
+
+	if synced && (next == nil || next.BlockSequenceNumber != 0) {
+		report.AddProblem(model.VerifyIncompleteChain, "", "", "Chain is marked as synced, but the first EntryBlock is not reached")
+	}
+
+	return report, nil
+
+}
+
+// verifyEntryBlock checks (and repairs if needed) header of entry block, that should precede next entry block
+func (c *Context) verifyEntryBlock(keyMR string, chainID string, next *model.EBlock, report *model.VerifyReport, repair bool) *model.EBlock {
+
+	log.Debug("Verify: Checking EntryBlock " + keyMR)
+
+	eblock := c.store.GetEBlock(&model.EBlock{KeyMR: keyMR})
+
+	if eblock == nil {
+		problem := report.AddProblem(model.VerifyMissingEBlock, keyMR, "", "EntryBlock not found into local DB")
+		if !repair {
+			return nil
+		}
+		_, err := c.parseEntryBlock(keyMR, false)
+		problem.SetRepairResult(err)
+		if err != nil {
+			return nil
+		}
+		return c.store.GetEBlock(&model.EBlock{KeyMR: keyMR})
+	}
+
+	if eblock.ChainID != chainID || (next != nil && eblock.BlockSequenceNumber != next.BlockSequenceNumber-1) {
+		problem := report.AddProblem(model.VerifyCorruptEBlock, keyMR, "", "EntryBlock header does not match the chain")
+		if repair {
+			fe, err := factom.GetEBlock(keyMR)
+			if err == nil {
+				eblock = model.NewEBlockFromFactomModel(keyMR, fe)
+				err = c.store.SaveEBlock(eblock)
+			}
+			problem.SetRepairResult(err)
+		}
+	}
+
+	return eblock
+
+}
+
+// verifyEntryBlockEntries recomputes hashes of entries bound to entry block and checks that its entry list is fully present
+func (c *Context) verifyEntryBlockEntries(eblock *model.EBlock, report *model.VerifyReport, repair bool) {
+
+	bound := make(map[string]bool)
+
+	for _, entry := range c.store.GetEBlockEntries(eblock) {
+
+		report.Entries++
+		bound[entry.EntryHash] = true
+
+		if entry.ChainID == eblock.ChainID && entry.Base64Decode().Hash() == entry.EntryHash {
+			continue
+		}
+
+		problem := report.AddProblem(model.VerifyCorruptEntry, eblock.KeyMR, entry.EntryHash, "Entry hash does not match its data")
+		if repair {
+			fe, err := factom.GetEntry(entry.EntryHash)
+			if err == nil {
+				err = c.store.RepairEntry(model.NewEntryFromFactomModel(fe).Base64Encode())
+			}
+			problem.SetRepairResult(err)
+		}
+
+	}
+
+	var problems []*model.VerifyProblem
+
+	if eblock.EntryList == nil {
+		problems = append(problems, report.AddProblem(model.VerifyUnknownEBlock, eblock.KeyMR, "", "Entry list of EntryBlock is not stored, so completeness can not be checked"))
+	}
+
+	for _, entryHash := range eblock.EntryList {
+		if !bound[entryHash] {
+			problems = append(problems, report.AddProblem(model.VerifyMissingEntry, eblock.KeyMR, entryHash, "Entry of EntryBlock not found into local DB"))
+		}
+	}
+
+	// re-parsing entry block fetches its entry list and all missing entries at once
+	if repair && len(problems) > 0 {
+		_, err := c.parseEntryBlock(eblock.KeyMR, false)
+		for _, problem := range problems {
+			problem.SetRepairResult(err)
+		}
+	}
+
+}
+
+// LookupEntry is high-level function, that run by api.GetEntry() with track=false
+// Entry is returned from local DB or from Factom, but neither entry nor its chain is stored into local DB
+func (c *Context) LookupEntry(entry *model.Entry) (*model.Entry, error) {
+
+	if localEntry := c.store.GetEntry(entry, ""); localEntry != nil {
+		return localEntry, nil
+	}
+
+	resp, err := entry.FillModelFromFactom()
+	if err != nil {
+		return nil, fmt.Errorf("Entry %s does not exist", entry.EntryHash)
+	}
+
+	resp.Status = resp.GetStatusFromFactom()
+
+	if resp.Status == model.EntryCompleted {
+		factomTime, err := resp.GetTimeFromFactom()
+		if err != nil {
+			log.Error(err)
+		} else {
+			t := time.Unix(factomTime, 0).UTC()
+			resp.FactomTime = &t
+		}
+	}
+
+	return resp.Base64Encode(), nil
+
+}
+
+// GetEntry is high-level function, that run by api.GetEntry()
+func (c *Context) GetEntry(entry *model.Entry, user *model.User) (*model.Entry, error) {
+
+	log.Debug("Search for entry into local DB")
+
+	// search for chain.ChainID into local DB
+	localentry := c.store.GetEntry(entry, "")
+
+	if localentry != nil {
+		log.Debug("Entry " + entry.EntryHash + " found into local DB")
+
+		log.Debug("Force binding chain ", localentry.ChainID, " to user ", user.Name)
+		err := c.store.BindChainToUser(localentry.GetChain(), user)
+		if err != nil {
+			log.Error(err)
+		}
+		// localentry already base64 encoded
+		return localentry, nil
+	}
+
+	log.Debug("Entry " + entry.EntryHash + " not found into local DB")
+	log.Debug("Search for entry on the blockchain")
+
+	resp, err := entry.FillModelFromFactom()
+
+	if err == nil {
+		log.Debug("Entry " + entry.EntryHash + " found on Factom")
+		resp.Status = resp.GetStatusFromFactom()
+
+		// search for chain.ChainID into local DB
+		localChain := c.store.GetChain(resp.GetChain())
+
+		if localChain == nil {
+			log.Debug("Chain " + resp.ChainID + " not found into local DB")
+			log.Debug("Creating chain into local DB")
+
+			chain := resp.GetChain()
+			chain.Status, chain.LatestEntryBlock = chain.GetStatusFromFactom()
+
+			// here we add existing Factom chain into local DB with factomTime = null
+			err = c.store.CreateChain(chain)
+			if err != nil {
+				log.Error(err)
+			}
+
+		}
+
+		log.Debug("Creating entry into local DB")
+
+		// get entry timestamp from Factom ONLY IF ENTRY STATUS IS COMPLETED
+		if resp.Status == model.EntryCompleted {
+			factomTime, err := resp.GetTimeFromFactom()
+			if err != nil {
+				log.Error(err)
+			} else {
+				t := time.Unix(factomTime, 0).UTC()
+				resp.FactomTime = &t
+			}
+		}
 
-		log.Debug("Chain " + entry.ChainID + " not found into local DB")
-		log.Debug("Checking if chain exists on Factom")
+		err = c.store.CreateEntry(resp.Base64Encode())
+		if err != nil {
+			log.Error(err)
+		}
 
-		if !entry.GetChain().Exists() {
-			log.Error("Chain " + entry.ChainID + " not found on Factom")
-			return nil, fmt.Errorf("Chain " + entry.ChainID + " not found")
+		log.Debug("Force binding chain ", resp.ChainID, " to user ", user.Name)
+		err = c.store.BindChainToUser(resp.GetChain(), user)
+		if err != nil {
+			log.Error(err)
 		}
 
+		return resp.Base64Encode(), nil
+	}
+
+	return nil, fmt.Errorf("Entry %s does not exist", entry.EntryHash)
+
+}
+
+// CreateEntry is high-level function, that run by api.CreateEntry()
+func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entry, error) {
+
+	entry = entry.Base64Decode()
+
+	chainIsLocal, err := c.validateEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !chainIsLocal {
+
 		log.Debug("Creating chain into local DB")
 
 		chain := entry.GetChain()
@@ -579,6 +1314,106 @@ func (c *Context) CreateEntry(entry *model.Entry, user *model.User) (*model.Entr
 	return entry.Base64Encode(), nil
 }
 
+// ValidateEntry is high-level function, that run by api.CreateEntry() with dryRun=true
+// Entry is validated the same way as by CreateEntry(), but nothing is written into local DB or queue
+func (c *Context) ValidateEntry(entry *model.Entry) (*model.Estimate, error) {
+
+	entry = entry.Base64Decode()
+
+	_, err := c.validateEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return estimateWrite(entry, model.QueueActionEntry), nil
+
+}
+
+// validateEntry checks if base64-decoded entry can be created & fills its EntryHash.
+// Returns true if chain of entry is found into local DB, false if it's found on Factom only.
+func (c *Context) validateEntry(entry *model.Entry) (bool, error) {
+
+	log.Debug("Checking if entry fits into 10KB")
+	_, err := entry.Fit10KB()
+	if err != nil {
+		log.Error(err)
+		return false, fmt.Errorf(err.Error())
+	}
+
+	entry.EntryHash = entry.Hash()
+
+	if c.store.GetChain(entry.GetChain()) != nil {
+		return true, nil
+	}
+
+	log.Debug("Chain " + entry.ChainID + " not found into local DB")
+	log.Debug("Checking if chain exists on Factom")
+
+	if !entry.GetChain().Exists() {
+		log.Error("Chain " + entry.ChainID + " not found on Factom")
+		return false, fmt.Errorf("Chain " + entry.ChainID + " not found")
+	}
+
+	return false, nil
+
+}
+
+// GetEntryReceipt is high-level function, that run by api.GetEntryReceipt()
+// Receipt is fetched from Factom only for completed entries and cached into local DB.
+// Cached receipt is refreshed until its directory block is anchored.
+func (c *Context) GetEntryReceipt(entry *model.Entry, user *model.User) (*model.Receipt, error) {
+
+	localEntry, err := c.GetEntry(entry, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if localEntry.Status != model.EntryCompleted {
+
+		log.Debug("Entry " + entry.EntryHash + " is not completed into local DB, checking status on Factom")
+
+		if entry.GetStatusFromFactom() != model.EntryCompleted {
+			return nil, nil
+		}
+
+		err = c.store.UpdateEntry(&model.Entry{EntryHash: entry.EntryHash, Status: model.EntryCompleted})
+		if err != nil {
+			log.Error(err)
+		}
+
+	}
+
+	receipt := c.store.GetReceipt(&model.Receipt{EntryHash: entry.EntryHash})
+
+	if receipt != nil && receipt.Anchored() {
+		log.Debug("Receipt of entry " + entry.EntryHash + " found into local DB")
+		return receipt, nil
+	}
+
+	log.Debug("Fetching receipt of entry " + entry.EntryHash + " from Factom")
+
+	fr, err := factom.GetReceipt(entry.EntryHash)
+	if err != nil {
+		// not anchored receipt from local DB is still valid
+		if receipt != nil {
+			log.Error(err)
+			return receipt, nil
+		}
+		return nil, err
+	}
+
+	receipt = model.NewReceiptFromFactomModel(fr)
+	receipt.EntryHash = entry.EntryHash
+
+	err = c.store.SaveReceipt(receipt)
+	if err != nil {
+		log.Error(err)
+	}
+
+	return receipt, nil
+
+}
+
 // addToQueue checks if task already exists into queue db and if not, then adds the task into queue db
 func (c *Context) addToQueue(params *model.QueueParams, action string, user *model.User) error {
 
@@ -616,14 +1451,7 @@ func (c *Context) GetQueueToProcess() []*model.Queue {
 
 }
 
-// GetQueueToClear gets tasks from queue, that was successfully processed more than 1 hour ago
-func (c *Context) GetQueueToClear() []*model.Queue {
-
-	return c.store.GetQueueWhere("result IS NOT NULL AND processed_at IS NOT NULL AND processed_at < NOW() - INTERVAL '1 hour'")
-
-}
-
-// ProcessQueue processes write task from queue: makes factomd commit+reveal request and update queue item according to response (success or error)
+// ProcessQueue processes write task from queue: makes factomd commit & reveal requests and update queue item according to response (success or error)
 func (c *Context) ProcessQueue(queue *model.Queue) error {
 
 	params := &model.QueueParams{}
@@ -637,12 +1465,21 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 	var processingIsSuccess bool
 	var resp string
 
+	// writes of user with own Es address are paid by user
+	userEC, err := c.getUserEC(&model.User{ID: queue.UserID})
+	if err != nil {
+		return err
+	}
+
 	switch queue.Action {
 	case model.QueueActionChain:
 		log.Debug(debugMessage)
 		chain := &model.Chain{}
 		copier.Copy(chain, params)
-		resp, err = c.wallet.CommitRevealChain(chain.ConvertToFactomModel())
+		fchain := chain.ConvertToFactomModel()
+		resp, err = c.commitReveal(queue,
+			func() (*wallet.Commit, error) { return c.wallet.CommitChain(fchain, queue.UserID, userEC) },
+			func() (string, error) { return c.wallet.RevealChain(fchain) })
 		if err != nil {
 			processingIsSuccess = false
 		} else {
@@ -660,7 +1497,10 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 		log.Debug(debugMessage)
 		entry := &model.Entry{}
 		copier.Copy(entry, params)
-		resp, err = c.wallet.CommitRevealEntry(entry.ConvertToFactomModel())
+		fentry := entry.ConvertToFactomModel()
+		resp, err = c.commitReveal(queue,
+			func() (*wallet.Commit, error) { return c.wallet.CommitEntry(fentry, queue.UserID, userEC) },
+			func() (string, error) { return c.wallet.RevealEntry(fentry) })
 		if err != nil {
 			processingIsSuccess = false
 		} else {
@@ -680,6 +1520,9 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 		queue.Result = resp
 		processedAt := time.Now()
 		queue.ProcessedAt = &processedAt
+		// status of entry on Factom is tracked by ack tracker from now on
+		nextAckAt := processedAt.Add(AckMinInterval)
+		queue.NextAckAt = &nextAckAt
 	} else {
 		log.Error("Queue processing: create " + queue.Action + " FAILED")
 		queue.TryCount++
@@ -698,57 +1541,69 @@ func (c *Context) ProcessQueue(queue *model.Queue) error {
 
 }
 
-// ClearQueue gets entry status from Factom, checks if it's 'completed' and then deletes task.
-// Otherwise it runs ProcessQueue() for force processing.
-func (c *Context) ClearQueue(queue *model.Queue) error {
+// commitReveal commits & reveals entry of queue task.
+// Commit is stored into queue before reveal, so if reveal fails, only reveal is retried while the commit is acknowledged by Factom.
+func (c *Context) commitReveal(queue *model.Queue, commit func() (*wallet.Commit, error), reveal func() (string, error)) (string, error) {
 
-	debugMessage := fmt.Sprintf("Queue clearing: ID=%d", queue.ID)
-	log.Debug(debugMessage)
+	if queue.State == model.QueueStateCommitted && !isCommitAcked(queue.CommitTxID) {
+		log.Warn("Queue processing: Commit " + queue.CommitTxID + " is not acknowledged by Factom, committing again")
+		queue.State = ""
+	}
 
-	params := &model.QueueParams{}
-	err := json.Unmarshal(queue.Params, &params)
+	if queue.State != model.QueueStateCommitted {
+		res, err := commit()
+		if err != nil {
+			return "", err
+		}
+		queue.State = model.QueueStateCommitted
+		queue.CommitTxID = res.TxID
+		queue.ECAddress = res.ECAddress
+		queue.ECCost += res.Cost
+		err = c.store.UpdateQueueFields(queue, map[string]interface{}{
+			"state":        queue.State,
+			"commit_tx_id": res.TxID,
+			"ec_address":   res.ECAddress,
+			"ec_cost":      gorm.Expr("ec_cost + ?", res.Cost),
+		})
+		if err != nil {
+			return "", err
+		}
+	} else {
+		log.Debug("Queue processing: Commit " + queue.CommitTxID + " is acknowledged, retrying reveal only")
+	}
+
+	resp, err := reveal()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	entry := &model.Entry{EntryHash: queue.Result}
+	queue.State = model.QueueStateRevealed
 
-	log.Debug("Queue clearing: Checking entry " + entry.EntryHash + " status")
-	entry.Status = entry.GetStatusFromFactom()
+	return resp, nil
 
-	log.Debug("Queue clearing: Entry status=" + entry.Status)
+}
 
-	if entry.Status == model.EntryCompleted {
-		log.Debug("Queue clearing: Soft delete row from queue table")
-		err := c.store.DeleteQueue(queue)
-		if err != nil {
-			log.Error(err)
-			return err
-		}
-	} else {
-		log.Debug("Queue clearing: Force processing this task again")
-		err := c.ProcessQueue(queue)
-		if err != nil {
-			log.Error(err)
-		}
-		processedAt := time.Now()
-		err = c.store.UpdateQueue(&model.Queue{ID: queue.ID, ProcessedAt: &processedAt})
-		if err != nil {
-			log.Error(err)
-			return err
-		}
+// isCommitAcked returns true if commit is known by Factom.
+// Empty txID means the commit was already known by Factom while committing.
+func isCommitAcked(txID string) bool {
+
+	if txID == "" {
+		return true
 	}
 
-	return nil
+	ack, err := factom.EntryCommitACK(txID, "")
+	if err != nil {
+		log.Debug(err)
+		return false
+	}
+
+	return ack.CommitData.Status == model.FactomEntryTransactionACK || ack.CommitData.Status == model.FactomEntryDBlockConfirmed
 
 }
 
 // ParseNewChainEntries fetches new entries of chain, that appeared on Factom inside all new entry blocks till chain.LatestEntryBlock
 func (c *Context) ParseNewChainEntries(chain *model.Chain) error {
 
-	var parseFrom string
-	var parseTo string
-
 	log.Debug("Updates parser: Checking chain " + chain.ChainID)
 
 	status, chainhead := chain.GetStatusFromFactom()
@@ -761,15 +1616,7 @@ func (c *Context) ParseNewChainEntries(chain *model.Chain) error {
 	// parse new entries if new blocks appeared
 	if chain.LatestEntryBlock != chainhead {
 		log.Debug("Updates parser: Chain " + chain.ChainID + " updated, parsing new entries")
-		parseFrom = chainhead
-		parseTo = chain.LatestEntryBlock
-		err := c.parseEntryBlocks(parseFrom, parseTo, false)
-		if err == nil {
-			err = c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: chainhead})
-			if err != nil {
-				return err
-			}
-		}
+		return c.parseChainUpdates(chain, chainhead)
 	} else {
 		log.Debug("Updates parser: No new entries found")
 	}
@@ -792,7 +1639,7 @@ func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int) error {
 
 	// if chain has not processed on Factom, don't touch it
 	if status != model.ChainCompleted {
-		return fmt.Errorf("History parse: Chain has not processed on Factom yet")
+		return errChainNotProcessed
 	}
 
 	t := true
@@ -811,10 +1658,28 @@ func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int) error {
 	if chain.EarliestEntryBlock != "" {
 		log.Debug("History parse: Start parsing from EntryBlock " + chain.EarliestEntryBlock)
 		parseFrom = chain.EarliestEntryBlock
+
+		// entry blocks appeared after the latest parsed one (e.g. chain was imported from archive) should not be skipped
+		if chain.LatestEntryBlock != "" && chain.LatestEntryBlock != chainhead {
+			log.Debug("History parse: Parsing new EntryBlocks till " + chain.LatestEntryBlock)
+			err := c.parseChainUpdates(chain, chainhead)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
+	// the next entryblock to fetch, used for ETA calculation (-1 if fetching from chainhead)
+	startSequence := int64(-1)
+	if chain.EarliestEntryBlock != "" {
+		if earliest := c.store.GetEBlock(&model.EBlock{KeyMR: chain.EarliestEntryBlock}); earliest != nil {
+			startSequence = earliest.BlockSequenceNumber
+		}
+	}
+	syncStartedAt := time.Now().UTC()
+
 	// set chain LatestEntryBlock & assign worker ID
-	c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: chainhead, WorkerID: workerID})
+	c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: chainhead, WorkerID: workerID, SyncStartedAt: &syncStartedAt, SyncStartSequence: startSequence})
 
 	// parsing chain entryblocks & entries recursively
 	err := c.parseEntryBlocks(parseFrom, parseTo, true)
@@ -840,12 +1705,46 @@ func (c *Context) ParseAllChainEntries(chain *model.Chain, workerID int) error {
 // While updates fetching (from ChainHead till latest parsed block), chain.EarliestEntryBlock is NOT being updated.
 func (c *Context) parseEntryBlocks(parseFrom string, parseTo string, updateEarliestEntryBlock bool) error {
 
+	var eb *factom.EBlock
+
+	// while updates fetching, entry blocks of chain must reach parseTo before its sequence number,
+	// otherwise entry blocks were replaced on Factom
+	var latest *model.EBlock
+	if !updateEarliestEntryBlock && parseTo != "" {
+		latest = c.store.GetEBlock(&model.EBlock{KeyMR: parseTo})
+	}
+
 	for ebhash := parseFrom; ebhash != parseTo; {
+
 		var err error
-		ebhash, err = c.parseEntryBlock(ebhash, updateEarliestEntryBlock)
+
+		if eb == nil {
+			log.Debug("Fetching EntryBlock " + ebhash)
+			eb, err = factom.GetEBlock(ebhash)
+			if err != nil {
+				return err
+			}
+		}
+
+		if latest != nil && eb.Header.BlockSequenceNumber <= latest.BlockSequenceNumber {
+			return errChainReorg
+		}
+
+		// previous entryblock is fetched together with entries of the current one
+		prevKeyMR := eb.Header.PrevKeyMR
+		if prevKeyMR == parseTo || prevKeyMR == factom.ZeroHash {
+			prevKeyMR = ""
+		}
+
+		var prev *factom.EBlock
+		prev, err = c.storeEntryBlock(ebhash, eb, updateEarliestEntryBlock, prevKeyMR)
 		if err != nil {
 			return err
 		}
+
+		ebhash = eb.Header.PrevKeyMR
+		eb = prev
+
 	}
 
 	return nil
@@ -861,58 +1760,125 @@ func (c *Context) parseEntryBlock(ebhash string, updateEarliestEntryBlock bool)
 	if err != nil {
 		return "", err
 	}
-	entryblock := model.NewEBlockFromFactomModel(ebhash, eb)
-	err = c.store.CreateEBlock(entryblock)
+
+	_, err = c.storeEntryBlock(ebhash, eb, updateEarliestEntryBlock, "")
 	if err != nil {
 		return "", err
 	}
 
-	var entry *model.Entry
-	var fistEntryOfEntryBlock *model.Entry
+	return eb.Header.PrevKeyMR, nil
 
-	for i, listItem := range eb.EntryList {
-		fe, err := factom.GetEntry(listItem.EntryHash)
-		if err != nil {
-			return "", err
-		}
-		entry = model.NewEntryFromFactomModel(fe)
-		log.Debug("Fetching Entry " + entry.EntryHash)
-		entry.Status = model.EntryCompleted
-		t := time.Unix(listItem.Timestamp, 0).UTC()
-		entry.FactomTime = &t
-		err = c.store.CreateEntry(entry.Base64Encode())
-		if err != nil {
-			log.Error(err)
-			return "", err
-		}
-		err = c.store.BindEntryToEBlock(entry, entryblock)
-		if err != nil {
+}
+
+// Fetches entries of the fetched entryblock and stores them into local DB.
+// If prevKeyMR is not empty, previous entryblock is fetched in the same batch & returned.
+func (c *Context) storeEntryBlock(ebhash string, eb *factom.EBlock, updateEarliestEntryBlock bool, prevKeyMR string) (*factom.EBlock, error) {
+
+	log.Debug("Fetching ", len(eb.EntryList), " entries of EntryBlock "+ebhash)
+
+	entries, prev, err := c.fetchEntryBlockEntries(eb, prevKeyMR)
+	if err != nil {
+		return nil, err
+	}
+
+	// entryblock, its entries and chain's EarliestEntryBlock are written atomically,
+	// so interrupted parsing always resumes from the entryblock, that is fully stored
+	err = c.store.WithTx(func(tx store.Store) error {
+		return saveEntryBlock(tx, ebhash, eb, entries, updateEarliestEntryBlock)
+	})
+	if err != nil {
+		if err != errSyncJobStopped {
 			log.Error(err)
-			return "", err
-		}
-		if i == 0 {
-			fistEntryOfEntryBlock = entry
 		}
+		return nil, err
 	}
 
+	return prev, nil
+
+}
+
+// saveEntryBlock writes fetched entryblock with its entries into local DB using store bound to DB transaction
+func saveEntryBlock(tx store.Store, ebhash string, eb *factom.EBlock, entries []*model.Entry, updateEarliestEntryBlock bool) error {
+
 	if updateEarliestEntryBlock == true {
-		err = c.store.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, EarliestEntryBlock: ebhash})
+		// chain is updated first, so its row is locked till commit & chain can't be reset in the middle
+		err := tx.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, EarliestEntryBlock: ebhash})
 		if err != nil {
-			return "", err
+			return err
+		}
+		// history fetching may be paused or cancelled by admin, or chain may be reset by rollback
+		if isSyncJobStopped(tx, eb.Header.ChainID) {
+			return errSyncJobStopped
 		}
 	}
 
+	err := tx.CreateEBlockWithEntries(model.NewEBlockFromFactomModel(ebhash, eb), entries)
+	if err != nil {
+		return err
+	}
+
 	// if we parsed the first entry block, set synced=true & update extIDs & set FactomTime to time of the block
 	if eb.Header.PrevKeyMR == factom.ZeroHash {
 		t := true
 		factomTime := time.Unix(eb.Header.Timestamp, 0).UTC()
 		// s[0] — first entry of the entry block
-		err = c.store.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, Synced: &t, ExtIDs: fistEntryOfEntryBlock.Base64Encode().ExtIDs, FactomTime: &factomTime, WorkerID: -2})
+		err = tx.UpdateChain(&model.Chain{ChainID: eb.Header.ChainID, Synced: &t, ExtIDs: entries[0].ExtIDs, FactomTime: &factomTime, WorkerID: -2})
 		if err != nil {
-			return "", err
+			return err
 		}
 	}
 
-	return eb.Header.PrevKeyMR, nil
+	return nil
+
+}
+
+// Fetches all entries of the entryblock and (optionally, if prevKeyMR is not empty) previous entryblock
+// using as few round trips to factomd as possible.
+// Entries are returned base64 encoded in the order of entryblock's EntryList.
+func (c *Context) fetchEntryBlockEntries(eb *factom.EBlock, prevKeyMR string) ([]*model.Entry, *factom.EBlock, error) {
+
+	var reqs []*factom.JSON2Request
+
+	for i, listItem := range eb.EntryList {
+		reqs = append(reqs, factom.NewJSON2Request("entry", i, map[string]string{"hash": listItem.EntryHash}))
+	}
+
+	if prevKeyMR != "" {
+		reqs = append(reqs, factom.NewJSON2Request("entry-block", len(reqs), map[string]string{"keymr": prevKeyMR}))
+	}
+
+	resps, err := c.factomdRequests(reqs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, resp := range resps {
+		if resp.Error != nil {
+			return nil, nil, resp.Error
+		}
+	}
+
+	entries := make([]*model.Entry, len(eb.EntryList))
+	for i, listItem := range eb.EntryList {
+		fe := new(factom.Entry)
+		if err := json.Unmarshal(resps[i].JSONResult(), fe); err != nil {
+			return nil, nil, err
+		}
+		entry := model.NewEntryFromFactomModel(fe)
+		entry.Status = model.EntryCompleted
+		t := time.Unix(listItem.Timestamp, 0).UTC()
+		entry.FactomTime = &t
+		entries[i] = entry.Base64Encode()
+	}
+
+	var prev *factom.EBlock
+	if prevKeyMR != "" {
+		prev = new(factom.EBlock)
+		if err := json.Unmarshal(resps[len(resps)-1].JSONResult(), prev); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return entries, prev, nil
 
 }
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/FactomProject/factom"
+	"testing"
+)
+
+func TestParseAllChainEntriesRollsBackFailedEntryBlock(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	chainID, first := newTestChain("ingest")
+
+	keyMRs := []string{f.addEBlock(chainID, factom.ZeroHash, 10, first, 2)}
+	keyMRs = append(keyMRs, f.addEBlock(chainID, keyMRs[0], 11, nil, 3))
+	keyMRs = append(keyMRs, f.addEBlock(chainID, keyMRs[1], 12, nil, 3))
+
+	s := newMemStore()
+	s.CreateChain(&model.Chain{ChainID: chainID, Status: model.ChainCompleted})
+
+	c := &Context{store: s}
+
+	// entries of the latest entry block are stored, the 2nd entry of the previous one fails
+	s.hooks.failEntryAt = 5
+
+	err := c.ParseAllChainEntries(s.GetChain(&model.Chain{ChainID: chainID}), 1)
+	if err == nil {
+		t.Fatal("expected error of the failed entry insert")
+	}
+
+	chain := s.GetChain(&model.Chain{ChainID: chainID})
+
+	if chain.EarliestEntryBlock != keyMRs[2] {
+		t.Fatalf("EarliestEntryBlock is %s, expected the latest fully stored EntryBlock %s", chain.EarliestEntryBlock, keyMRs[2])
+	}
+
+	if s.GetEBlock(&model.EBlock{KeyMR: keyMRs[1]}) != nil {
+		t.Error("failed EntryBlock is not rolled back")
+	}
+
+	if len(s.data.bindings[keyMRs[1]]) > 0 {
+		t.Error("entries of failed EntryBlock are still bound to it")
+	}
+
+	for _, item := range f.eblocks[keyMRs[1]].EntryList {
+		if _, ok := s.data.entries[item.EntryHash]; ok {
+			t.Errorf("entry %s of failed EntryBlock is not rolled back", item.EntryHash)
+		}
+	}
+
+	if chain.Synced != nil && *chain.Synced {
+		t.Error("chain is marked as synced after failure")
+	}
+
+	s.hooks.failEntryAt = 0
+	f.requests()
+
+	err = c.ParseAllChainEntries(chain, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requested := f.requests(); len(requested) == 0 || requested[0] != chain.EarliestEntryBlock {
+		t.Errorf("parsing is not resumed from EntryBlock %s, requested %v", chain.EarliestEntryBlock, requested)
+	}
+
+	total := 0
+
+	for _, keyMR := range keyMRs {
+
+		if s.GetEBlock(&model.EBlock{KeyMR: keyMR}) == nil {
+			t.Errorf("EntryBlock %s is not stored", keyMR)
+		}
+
+		list := f.eblocks[keyMR].EntryList
+		total += len(list)
+
+		bound := s.data.bindings[keyMR]
+		if len(bound) != len(list) {
+			t.Errorf("EntryBlock %s has %d bound entries, expected %d", keyMR, len(bound), len(list))
+			continue
+		}
+
+		for i, item := range list {
+			if bound[i] != item.EntryHash {
+				t.Errorf("EntryBlock %s: entry #%d is %s, expected %s", keyMR, i, bound[i], item.EntryHash)
+			}
+		}
+
+	}
+
+	if len(s.data.entries) != total {
+		t.Errorf("%d entries stored, expected %d", len(s.data.entries), total)
+	}
+
+	chain = s.GetChain(&model.Chain{ChainID: chainID})
+
+	if chain.Synced == nil || !*chain.Synced {
+		t.Error("chain is not marked as synced")
+	}
+
+	if chain.EarliestEntryBlock != keyMRs[0] {
+		t.Errorf("EarliestEntryBlock is %s, expected %s", chain.EarliestEntryBlock, keyMRs[0])
+	}
+
+	if len(chain.ExtIDs) != 2 || chain.ExtIDs[1] != base64.StdEncoding.EncodeToString(first.ExtIDs[1]) {
+		t.Errorf("ExtIDs of chain %v are not taken from the first entry", chain.ExtIDs)
+	}
+
+}
+
+// archive returns NDJSON archive of entry blocks on fake factomd, entries of every entry block are in reverse order.
+// tamper is called for every exported entry before it's written.
+func (f *fakeFactomd) archive(tamper func(*model.ExportEntry), keyMRs ...string) *bytes.Buffer {
+
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+
+	for _, keyMR := range keyMRs {
+		eb := f.eblocks[keyMR]
+		for i := len(eb.EntryList) - 1; i >= 0; i-- {
+			entry := model.NewEntryFromFactomModel(f.entries[eb.EntryList[i].EntryHash]).Base64Encode()
+			item := &model.ExportEntry{EntryHash: entry.EntryHash, ChainID: entry.ChainID, ExtIDs: entry.ExtIDs, Content: entry.Content}
+			item.EBlock = model.NewEBlockFromFactomModel(keyMR, eb)
+			item.EBlock.EntryList = nil
+			if tamper != nil {
+				tamper(item)
+			}
+			enc.Encode(item)
+		}
+	}
+
+	return buf
+
+}
+
+func TestImportChainVerifiesArchiveAgainstFactomd(t *testing.T) {
+
+	f := newFakeFactomd()
+	defer f.Close()
+
+	chainID, first := newTestChain("import")
+
+	e0 := f.addEBlock(chainID, factom.ZeroHash, 10, first, 2)
+	e1 := f.addEBlock(chainID, e0, 11, nil, 3)
+
+	s := newMemStore()
+	c := &Context{store: s}
+
+	chain := &model.Chain{ChainID: chainID}
+
+	// header of archive entry block differs from Factom
+	_, err := c.ImportChain(chain, f.archive(func(item *model.ExportEntry) {
+		if item.EBlock.KeyMR == e1 {
+			item.EBlock.DBHeight = 12
+		}
+	}, e1, e0))
+	if err == nil {
+		t.Error("expected error of the tampered EntryBlock header")
+	}
+
+	// archive misses entry of entry block
+	missing := f.eblocks[e1].EntryList[1].EntryHash
+	archive := &bytes.Buffer{}
+	for _, line := range bytes.SplitAfter(f.archive(nil, e1, e0).Bytes(), []byte("\n")) {
+		if !bytes.Contains(line, []byte(missing)) {
+			archive.Write(line)
+		}
+	}
+	if _, err = c.ImportChain(chain, archive); err == nil {
+		t.Error("expected error of the missing entry")
+	}
+
+	if s.GetChain(chain) != nil {
+		t.Fatal("chain is created from invalid archive")
+	}
+
+	result, err := c.ImportChain(chain, f.archive(nil, e1, e0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !result.Synced || result.EBlocks != 2 || result.Entries != 6 {
+		t.Errorf("unexpected import result %+v", result)
+	}
+
+	for _, keyMR := range []string{e0, e1} {
+
+		list := f.eblocks[keyMR].EntryList
+
+		eblock := s.GetEBlock(&model.EBlock{KeyMR: keyMR})
+		if eblock == nil || len(eblock.EntryList) != len(list) {
+			t.Errorf("EntryBlock %s is not stored with its EntryList", keyMR)
+			continue
+		}
+
+		bound := s.data.bindings[keyMR]
+		for i, item := range list {
+			if eblock.EntryList[i] != item.EntryHash || bound[i] != item.EntryHash {
+				t.Errorf("EntryBlock %s: entry #%d is not %s", keyMR, i, item.EntryHash)
+			}
+		}
+
+	}
+
+	stored := s.GetChain(chain)
+
+	if stored.LatestEntryBlock != e1 || stored.EarliestEntryBlock != e0 {
+		t.Errorf("LatestEntryBlock=%s, EarliestEntryBlock=%s, expected %s, %s", stored.LatestEntryBlock, stored.EarliestEntryBlock, e1, e0)
+	}
+
+	if len(stored.ExtIDs) != 2 || stored.ExtIDs[1] != base64.StdEncoding.EncodeToString(first.ExtIDs[1]) {
+		t.Errorf("ExtIDs of chain %v are not taken from the first entry", stored.ExtIDs)
+	}
+
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/store"
+	"github.com/jinzhu/gorm"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// sync job is failed after this number of failed attempts & is not retried until resumed by admin
+	SyncJobMaxAttempts = 5
+)
+
+var (
+	errChainNotProcessed = fmt.Errorf("History parse: Chain has not processed on Factom yet")
+	errSyncJobStopped    = fmt.Errorf("History parse: Sync job stopped")
+)
+
+// GetChainsToSync returns chains with pending sync jobs, that should be sent into history fetching pool.
+// Sync jobs are created for unsynced chains, that don't have them yet,
+// done sync jobs of chains, that fell out of sync, become pending again.
+func (c *Context) GetChainsToSync() []*model.Chain {
+
+	err := c.store.CreateMissingSyncJobs()
+	if err != nil {
+		log.Error(err)
+	}
+
+	err = c.store.RestartDoneSyncJobs()
+	if err != nil {
+		log.Error(err)
+	}
+
+	return c.store.GetChainsBySyncJobState(model.SyncJobPending)
+
+}
+
+// RunSyncJob runs history fetching of chain by worker & updates state of its sync job.
+// Only pending job is run, the job is retried until SyncJobMaxAttempts failed attempts.
+func (c *Context) RunSyncJob(chain *model.Chain, workerID int) error {
+
+	// chain may be synced by other way (e.g. imported) after sending into pool
+	if localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID}); localChain != nil && localChain.Synced != nil && *localChain.Synced {
+		return c.store.TransitSyncJob(chain.ChainID, []string{model.SyncJobPending}, map[string]interface{}{"state": model.SyncJobDone})
+	}
+
+	now := time.Now().UTC()
+	err := c.store.TransitSyncJob(chain.ChainID, []string{model.SyncJobPending}, map[string]interface{}{"state": model.SyncJobRunning, "attempts": gorm.Expr("attempts + 1"), "started_at": &now})
+	if err != nil {
+		// job was paused or cancelled after sending into pool
+		log.Debug(err)
+		return c.ResetChainParsing(chain)
+	}
+
+	err = c.ParseAllChainEntries(chain, workerID)
+
+	fields := map[string]interface{}{"finished_at": time.Now().UTC()}
+
+	switch err {
+	case nil:
+		fields["state"] = model.SyncJobDone
+		fields["last_error"] = ""
+	case errSyncJobStopped:
+		// state was already changed by admin
+		log.Info("History parse: Sync job of chain ", chain.ChainID, " stopped")
+		return c.ResetChainParsing(chain)
+	case errChainNotProcessed:
+		// chain is not processed on Factom yet, so it's not a failed attempt
+		fields["state"] = model.SyncJobPending
+		fields["attempts"] = gorm.Expr("attempts - 1")
+	default:
+		log.Error(err)
+		job := c.store.GetSyncJob(&model.SyncJob{ChainID: chain.ChainID})
+		fields["state"] = model.SyncJobPending
+		if job != nil && job.Attempts >= SyncJobMaxAttempts {
+			fields["state"] = model.SyncJobFailed
+		}
+		fields["last_error"] = err.Error()
+	}
+
+	if err != nil {
+		if resetErr := c.ResetChainParsing(chain); resetErr != nil {
+			log.Error(resetErr)
+		}
+	}
+
+	// job may be paused or cancelled while running, so its state is kept
+	if transitErr := c.store.TransitSyncJob(chain.ChainID, []string{model.SyncJobRunning}, fields); transitErr != nil {
+		log.Debug(transitErr)
+	}
+
+	return err
+
+}
+
+// PauseChainSync is high-level function, that run by api.pauseChainSync()
+// Paused chain is not sent into the pool, the running sync is stopped after the current entry block
+func (c *Context) PauseChainSync(chain *model.Chain) (*model.Chain, error) {
+
+	return c.stopChainSync(chain, []string{model.SyncJobPending, model.SyncJobRunning}, map[string]interface{}{"state": model.SyncJobPaused})
+
+}
+
+// ResumeChainSync is high-level function, that run by api.resumeChainSync()
+// Paused, failed & cancelled sync jobs become pending with reset attempts
+func (c *Context) ResumeChainSync(chain *model.Chain) (*model.Chain, error) {
+
+	return c.transitChainSync(chain, []string{model.SyncJobPaused, model.SyncJobFailed, model.SyncJobCancelled}, map[string]interface{}{"state": model.SyncJobPending, "attempts": 0, "last_error": ""})
+
+}
+
+// CancelChainSync is high-level function, that run by api.cancelChainSync()
+// Cancelled chain is not synced until resumed or resynced, already fetched data is kept
+func (c *Context) CancelChainSync(chain *model.Chain) (*model.Chain, error) {
+
+	return c.stopChainSync(chain, []string{model.SyncJobPending, model.SyncJobRunning, model.SyncJobPaused, model.SyncJobFailed}, map[string]interface{}{"state": model.SyncJobCancelled})
+
+}
+
+// ResyncChain is high-level function, that run by api.resyncChain()
+// All entry blocks & fetched entries of chain are deleted from local DB & chain is synced from scratch
+func (c *Context) ResyncChain(chain *model.Chain) (*model.Chain, error) {
+
+	localChain := c.store.GetChain(&model.Chain{ChainID: chain.ChainID})
+	if localChain == nil {
+		return nil, fmt.Errorf("Chain %s not found into local DB", chain.ChainID)
+	}
+
+	job := c.store.GetSyncJob(&model.SyncJob{ChainID: chain.ChainID})
+	if localChain.WorkerID > 0 || (job != nil && job.State == model.SyncJobRunning) {
+		return nil, fmt.Errorf("Chain %s is being synced by worker, pause or cancel its sync job and try again", chain.ChainID)
+	}
+
+	status, chainhead := localChain.GetStatusFromFactom()
+	if status != model.ChainCompleted {
+		return nil, errChainNotProcessed
+	}
+
+	log.Info("Resync: Deleting local data of chain ", chain.ChainID)
+
+	// chain.LatestEntryBlock is set to chainhead, so updates parser doesn't parse the whole chain
+	err := resetChainData(c.store, localChain, chainhead)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetChainSyncStatus(chain), nil
+
+}
+
+// resetChainData deletes local data of chain & sets its sync job to pending, so chain is synced from scratch.
+// Unlike ResyncChain(), chain held by worker is reset too: its running sync job becomes pending,
+// so worker stops before storing the next entry block (see saveEntryBlock()) & chain is sent into the pool again.
+// latestEntryBlock is set as chain.LatestEntryBlock, chain with empty one is parsed only by history fetching.
+func resetChainData(s store.Store, chain *model.Chain, latestEntryBlock string) error {
+
+	return s.WithTx(func(tx store.Store) error {
+
+		err := tx.DeleteChainData(&model.Chain{ChainID: chain.ChainID, LatestEntryBlock: latestEntryBlock})
+		if err != nil {
+			return err
+		}
+
+		if tx.GetSyncJob(&model.SyncJob{ChainID: chain.ChainID}) == nil {
+			return tx.CreateMissingSyncJobs()
+		}
+
+		states := []string{model.SyncJobPending, model.SyncJobRunning, model.SyncJobPaused, model.SyncJobFailed, model.SyncJobDone, model.SyncJobCancelled}
+
+		return tx.TransitSyncJob(chain.ChainID, states, map[string]interface{}{"state": model.SyncJobPending, "attempts": 0, "last_error": "", "size_estimate": -1})
+
+	})
+
+}
+
+func (c *Context) transitChainSync(chain *model.Chain, from []string, fields map[string]interface{}) (*model.Chain, error) {
+
+	err := c.store.TransitSyncJob(chain.ChainID, from, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.GetChainSyncStatus(chain), nil
+
+}
+
+// stopChainSync transits sync job of chain into paused or cancelled state.
+// Chain, that is waiting in the pool queue, is not marked as sent to pool anymore & is removed from queue by fetchUnsyncedChains(),
+// running sync is stopped by worker itself.
+func (c *Context) stopChainSync(chain *model.Chain, from []string, fields map[string]interface{}) (*model.Chain, error) {
+
+	job := c.store.GetSyncJob(&model.SyncJob{ChainID: chain.ChainID})
+
+	res, err := c.transitChainSync(chain, from, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	if job != nil && job.State == model.SyncJobPending {
+		f := false
+		err = c.store.UpdateChain(&model.Chain{ChainID: chain.ChainID, SentToPool: &f})
+		if err != nil {
+			log.Error(err)
+		}
+	}
+
+	return res, nil
+
+}
+
+// isSyncJobStopped returns true if sync job of chain is not running anymore (paused or cancelled by admin, or reset by rollback)
+func isSyncJobStopped(s store.Store, chainID string) bool {
+
+	job := s.GetSyncJob(&model.SyncJob{ChainID: chainID})
+
+	return job != nil && job.State != model.SyncJobRunning
+
+}
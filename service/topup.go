@@ -0,0 +1,145 @@
+package service
+
+import (
+	"github.com/DeFacto-Team/Factom-Open-API/model"
+	"github.com/DeFacto-Team/Factom-Open-API/wallet"
+	"github.com/FactomProject/factom"
+	"github.com/jinzhu/copier"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// submitted purchase, which transaction is not known by factomd during this period, is considered dropped
+	TopUpDropTimeout = 10 * time.Minute
+	// purchase is not retried during this period after failed one
+	TopUpRetryDelay = 10 * time.Minute
+	// number of EC purchases shown by admin endpoint
+	ECPurchasesLimit = 100
+)
+
+// GetECPurchases is high-level function, that run by api.getECPurchases()
+// Returns latest records of EC purchases audit log
+func (c *Context) GetECPurchases() []*model.ECPurchase {
+
+	return c.store.GetECPurchases(ECPurchasesLimit)
+
+}
+
+// topUpBalances buys EC from Fs address set in config for server EC addresses with balance below threshold.
+// Every purchase, successful or not, is stored into audit log.
+func (c *Context) topUpBalances(status *model.WalletStatus) {
+
+	conf := c.conf.Wallet
+
+	if conf.TopUpFsAddress == "" || conf.TopUpThreshold <= 0 {
+		return
+	}
+
+	for _, address := range status.ECAddresses {
+
+		if address.Balance >= conf.TopUpThreshold {
+			continue
+		}
+
+		if latest := c.store.GetLatestECPurchase(address.Address); latest != nil && c.isTopUpPending(latest) {
+			log.Debug("Wallet top-up: Purchase ", latest.ID, " for EC address ", address.Address, " is ", latest.Status, ", skipping")
+			continue
+		}
+
+		amount := conf.TopUpTarget - address.Balance
+
+		if conf.TopUpDailyLimit > 0 {
+			// daily limit can't be checked, so nothing is bought
+			purchased, err := c.store.GetECPurchasedSince(time.Now().Add(-24 * time.Hour))
+			if err != nil {
+				log.Error("Wallet top-up: Can not check daily limit: ", err)
+				return
+			}
+			left := conf.TopUpDailyLimit - purchased
+			if left <= 0 {
+				log.Warn("Wallet top-up: Daily limit of ", conf.TopUpDailyLimit, " EC reached, EC address ", address.Address, " is not topped up")
+				return
+			}
+			if amount > left {
+				amount = left
+			}
+		}
+
+		purchase := &model.ECPurchase{ECAddress: address.Address, ECAmount: amount}
+
+		res, err := wallet.PurchaseEC(conf.TopUpFsAddress, address.Address, amount)
+
+		// submitting may fail after factomd accepted transaction, so purchase is failed only if transaction is unknown.
+		// If status can't be checked, purchase is considered submitted & dropped later by isTopUpPending(), if it's unknown.
+		if err != nil && res != nil {
+			if ack, ackErr := factom.FactoidACK(res.TxID, ""); ackErr != nil || ack.Status != model.FactomEntryUnknown {
+				log.Warn("Wallet top-up: Submitting transaction ", res.TxID, " failed, but it may be accepted by Factom: ", err)
+				err = nil
+			}
+		}
+
+		if err != nil {
+			log.Error("Wallet top-up: Buying ", amount, " EC for EC address ", address.Address, " FAILED: ", err)
+			if res != nil {
+				purchase.TxID = res.TxID
+			}
+			purchase.Status = model.ECPurchaseFailed
+			purchase.Error = err.Error()
+		} else {
+			log.Info("Wallet top-up: Bought ", res.ECAmount, " EC for EC address ", res.ECAddress, ", txid=", res.TxID)
+			copier.Copy(purchase, res)
+			purchase.Status = model.ECPurchaseSubmitted
+		}
+
+		if err := c.store.CreateECPurchase(purchase); err != nil {
+			log.Error(err)
+		}
+
+	}
+
+}
+
+// isTopUpPending returns true, if EC address should not be topped up, while its latest purchase is not settled:
+// transaction is not confirmed on Factom yet (or was confirmed after balance was fetched), or purchase failed recently.
+// Status of submitted purchase is checked on Factom & updated into audit log.
+func (c *Context) isTopUpPending(purchase *model.ECPurchase) bool {
+
+	if purchase.Status == model.ECPurchaseFailed {
+		return time.Since(purchase.CreatedAt) < TopUpRetryDelay
+	}
+
+	if purchase.Status != model.ECPurchaseSubmitted {
+		return false
+	}
+
+	ack, err := factom.FactoidACK(purchase.TxID, "")
+	if err != nil {
+		log.Error("Wallet top-up: Can not check status of transaction ", purchase.TxID, ": ", err)
+		return true
+	}
+
+	switch ack.Status {
+	case model.FactomEntryDBlockConfirmed:
+		log.Info("Wallet top-up: Transaction ", purchase.TxID, " confirmed")
+		purchase.Status = model.ECPurchaseConfirmed
+	case model.FactomEntryUnknown, model.FactomEntryNotConfirmed:
+		if time.Since(purchase.CreatedAt) < TopUpDropTimeout {
+			return true
+		}
+		log.Warn("Wallet top-up: Transaction ", purchase.TxID, " was dropped by Factom")
+		purchase.Status = model.ECPurchaseDropped
+		purchase.Error = "Transaction was dropped by Factom"
+	default:
+		return true
+	}
+
+	if err := c.store.UpdateECPurchase(purchase); err != nil {
+		log.Error(err)
+		return true
+	}
+
+	// balance was fetched before confirmation was checked, so confirmed purchase is reflected by the next refresh
+	return purchase.Status == model.ECPurchaseConfirmed
+
+}
@@ -1,7 +1,9 @@
 package store
 
 import (
+	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/DeFacto-Team/Factom-Open-API/config"
 	"github.com/DeFacto-Team/Factom-Open-API/model"
@@ -13,6 +15,7 @@ import (
 
 type Store interface {
 	Close() error
+	WithTx(fn func(tx Store) error) error
 
 	CreateUser(user *model.User) error
 	GetUser(user *model.User) *model.User
@@ -20,35 +23,76 @@ type Store interface {
 	UpdateUser(user *model.User) error
 	DeleteUser(user *model.User) error
 	DisableUserUsageLimit(chain *model.User) error
+	RemoveUserEsAddress(user *model.User) error
 
 	GetChain(chain *model.Chain) *model.Chain
 	GetChains(chain *model.Chain) []*model.Chain
+	GetChainsBySyncJobState(states ...string) []*model.Chain
+	GetChainsByIDs(chainIDs []string, chain *model.Chain) []*model.Chain
+	GetChainsWithEBlocksAfter(dbHeight int64) []*model.Chain
+	CountChainEBlocksAndEntries(chain *model.Chain) (int, int)
 	GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	SearchUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int)
 	GetChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int)
 	SearchChainEntries(chain *model.Chain, entry *model.Entry, start int, limit int, sort string) ([]*model.Entry, int)
+	ExportChainEntries(chain *model.Chain, fn func(*model.ExportEntry) error) error
 	CreateChain(chain *model.Chain) error
 	UpdateChain(chain *model.Chain) error
 	UpdateChainsWhere(sql string, chain *model.Chain) error
+	SetChainSyncPriority(chain *model.Chain, priority int) error
+	DeleteChainData(chain *model.Chain) error
+	RollbackChain(chain *model.Chain, ancestor *model.EBlock) error
 	BindChainToUser(chain *model.Chain, user *model.User) error
+	UnbindChainFromUser(chain *model.Chain, user *model.User) error
+	GetUntrackedChains(before time.Time) []*model.Chain
+	DeleteUntrackedChain(chain *model.Chain, before time.Time) (bool, error)
 
 	GetEntry(entry *model.Entry, sort string) *model.Entry
 	CreateEntry(entry *model.Entry) error
 	UpdateEntry(entry *model.Entry) error
+	RepairEntry(entry *model.Entry) error
+	GetEBlock(eblock *model.EBlock) *model.EBlock
+	GetEBlockEntries(eblock *model.EBlock) []*model.Entry
+	GetChainLatestEBlock(chain *model.Chain, maxDBHeight int64) *model.EBlock
 	CreateEBlock(eblock *model.EBlock) error
+	SaveEBlock(eblock *model.EBlock) error
 	BindEntryToEBlock(entry *model.Entry, eblock *model.EBlock) error
+	CreateEBlockWithEntries(eblock *model.EBlock, entries []*model.Entry) error
+
+	GetDBlock(dblock *model.DBlock) *model.DBlock
+	GetLatestDBlock() *model.DBlock
+	SaveDBlock(dblock *model.DBlock) error
+	DeleteDBlocksAfter(height int64) error
+
+	GetSyncJob(job *model.SyncJob) *model.SyncJob
+	CreateMissingSyncJobs() error
+	RestartDoneSyncJobs() error
+	TransitSyncJob(chainID string, from []string, fields map[string]interface{}) error
+
+	GetReceipt(receipt *model.Receipt) *model.Receipt
+	SaveReceipt(receipt *model.Receipt) error
 
 	GetQueue(queue *model.Queue) []*model.Queue
 	GetQueueWhere(sql string) []*model.Queue
 	GetQueueItem(queue *model.Queue) *model.Queue
 	CreateQueue(queue *model.Queue) error
 	UpdateQueue(queue *model.Queue) error
+	UpdateQueueFields(queue *model.Queue, fields map[string]interface{}) error
+	GetECSpending() map[string]int64
 	DeleteQueue(queue *model.Queue) error
+
+	CreateECPurchase(purchase *model.ECPurchase) error
+	UpdateECPurchase(purchase *model.ECPurchase) error
+	GetECPurchases(limit int) []*model.ECPurchase
+	GetLatestECPurchase(ecAddress string) *model.ECPurchase
+	GetECPurchasedSince(since time.Time) (int64, error)
 }
 
 // Контекст стореджа
 type Context struct {
 	db *gorm.DB
+	// true if db is a transaction
+	inTx bool
 }
 
 // Create new store
@@ -81,7 +125,7 @@ func NewStore(conf *config.Config, applyMigration bool) (Store, error) {
 		log.Info("Store: applied ", n, " migration(s)")
 	}
 
-	return &Context{db}, nil
+	return &Context{db: db}, nil
 
 }
 
@@ -92,6 +136,36 @@ func (c *Context) Close() error {
 
 }
 
+// WithTx runs fn with store, that executes all queries in a single DB transaction.
+// Transaction is committed if fn returns nil, otherwise it's rolled back.
+// Nested calls run in the already opened transaction.
+func (c *Context) WithTx(fn func(tx Store) error) (err error) {
+
+	if c.inTx {
+		return fn(c)
+	}
+
+	tx := c.db.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(&Context{db: tx, inTx: true}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+
+}
+
 func (c *Context) CreateUser(user *model.User) error {
 
 	if c.db.Create(&user).RowsAffected > 0 {
@@ -148,6 +222,16 @@ func (c *Context) DisableUserUsageLimit(user *model.User) error {
 
 }
 
+func (c *Context) RemoveUserEsAddress(user *model.User) error {
+
+	if c.db.Model(user).Updates(map[string]interface{}{"es_address": "", "ec_address": ""}).RowsAffected > 0 {
+		return nil
+	}
+
+	return fmt.Errorf("DB: Removing user Es address failed")
+
+}
+
 func (c *Context) GetChain(chain *model.Chain) *model.Chain {
 
 	res := &model.Chain{}
@@ -166,6 +250,55 @@ func (c *Context) GetChains(chain *model.Chain) []*model.Chain {
 
 }
 
+// GetChainsBySyncJobState returns chains, which sync jobs are in one of states
+func (c *Context) GetChainsBySyncJobState(states ...string) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Joins("JOIN sync_jobs ON sync_jobs.chain_id = chains.chain_id").
+		Where("sync_jobs.state IN (?)", states).
+		Order("chains.created_at ASC").
+		Find(&res)
+	return res
+
+}
+
+// GetChainsByIDs returns chains with chainIDs from the list, filtered by chain fields
+func (c *Context) GetChainsByIDs(chainIDs []string, chain *model.Chain) []*model.Chain {
+
+	res := []*model.Chain{}
+	if len(chainIDs) == 0 {
+		return res
+	}
+	c.db.Where("chain_id IN (?)", chainIDs).Where(chain).Find(&res)
+	return res
+
+}
+
+// GetChainsWithEBlocksAfter returns chains, that have entry blocks included into directory blocks after dbHeight
+func (c *Context) GetChainsWithEBlocksAfter(dbHeight int64) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Where("chain_id IN (SELECT chain_id FROM e_blocks WHERE db_height > ?)", dbHeight).Find(&res)
+	return res
+
+}
+
+// CountChainEBlocksAndEntries returns number of entry blocks of chain & entries bound to them into local DB
+func (c *Context) CountChainEBlocksAndEntries(chain *model.Chain) (int, int) {
+
+	var eblocks, entries int
+
+	c.db.Model(&model.EBlock{}).Where("chain_id = ?", chain.ChainID).Count(&eblocks)
+
+	c.db.Table("entries_e_blocks").
+		Joins("JOIN e_blocks ON e_blocks.key_mr = entries_e_blocks.e_block_key_mr").
+		Where("e_blocks.chain_id = ?", chain.ChainID).
+		Count(&entries)
+
+	return eblocks, entries
+
+}
+
 func (c *Context) GetUserChains(chain *model.Chain, user *model.User, start int, limit int, sort string) ([]*model.Chain, int) {
 
 	orderString := fmt.Sprintf("factom_time %s, created_at %s", sort, sort)
@@ -271,6 +404,61 @@ func (c *Context) SearchChainEntries(chain *model.Chain, entry *model.Entry, sta
 
 }
 
+// ExportChainEntries iterates over all local entries of chain (with their entry blocks) in the chain order
+// and calls fn for every row, so the whole chain is never loaded into memory.
+// Entries of entry block are ordered by their position into its EntryList,
+// entries not bound to any entry block (or bound to one without EntryList) are ordered by time.
+func (c *Context) ExportChainEntries(chain *model.Chain, fn func(*model.ExportEntry) error) error {
+
+	rows, err := c.db.Table("entries").
+		Select("entries.entry_hash, entries.chain_id, entries.ext_ids, entries.content, entries.factom_time, "+
+			"e_blocks.key_mr, e_blocks.block_sequence_number, e_blocks.prev_key_mr, e_blocks.timestamp, e_blocks.db_height").
+		Joins("LEFT JOIN entries_e_blocks ON entries_e_blocks.entry_entry_hash = entries.entry_hash").
+		Joins("LEFT JOIN e_blocks ON e_blocks.key_mr = entries_e_blocks.e_block_key_mr").
+		Where("entries.chain_id = ? AND entries.deleted_at IS NULL", chain.ChainID).
+		Order("e_blocks.block_sequence_number ASC NULLS LAST, array_position(e_blocks.entry_list, entries.entry_hash::text) ASC NULLS LAST, " +
+			"entries.factom_time ASC, entries.created_at ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+
+		entry := &model.ExportEntry{}
+
+		var keyMR, prevKeyMR sql.NullString
+		var sequence, timestamp, dbHeight sql.NullInt64
+
+		err = rows.Scan(&entry.EntryHash, &entry.ChainID, &entry.ExtIDs, &entry.Content, &entry.FactomTime,
+			&keyMR, &sequence, &prevKeyMR, &timestamp, &dbHeight)
+		if err != nil {
+			return err
+		}
+
+		// entries from queue are not bound to any entry block yet
+		if keyMR.Valid {
+			entry.EBlock = &model.EBlock{
+				KeyMR:               keyMR.String,
+				BlockSequenceNumber: sequence.Int64,
+				ChainID:             entry.ChainID,
+				PrevKeyMR:           prevKeyMR.String,
+				Timestamp:           timestamp.Int64,
+				DBHeight:            dbHeight.Int64,
+			}
+		}
+
+		if err = fn(entry); err != nil {
+			return err
+		}
+
+	}
+
+	return rows.Err()
+
+}
+
 func (c *Context) CreateEntry(entry *model.Entry) error {
 
 	assign := model.Entry{}
@@ -295,9 +483,64 @@ func (c *Context) UpdateEntry(entry *model.Entry) error {
 
 }
 
+// RepairEntry overwrites ExtIDs & Content of existing entry (even with empty values)
+func (c *Context) RepairEntry(entry *model.Entry) error {
+
+	update := map[string]interface{}{"chain_id": entry.ChainID, "ext_ids": entry.ExtIDs, "content": entry.Content}
+
+	if c.db.Model(&entry).Updates(update).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Repairing entry failed")
+
+}
+
+func (c *Context) GetEBlock(eblock *model.EBlock) *model.EBlock {
+
+	res := &model.EBlock{}
+	if c.db.First(&res, eblock).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+func (c *Context) GetEBlockEntries(eblock *model.EBlock) []*model.Entry {
+
+	res := []*model.Entry{}
+	c.db.Model(eblock).Related(&res, "Entries")
+	return res
+
+}
+
+// GetChainLatestEBlock returns entry block of chain with max sequence number, included into directory block <= maxDBHeight
+func (c *Context) GetChainLatestEBlock(chain *model.Chain, maxDBHeight int64) *model.EBlock {
+
+	res := &model.EBlock{}
+	if c.db.Where("chain_id = ? AND db_height <= ?", chain.ChainID, maxDBHeight).Order("block_sequence_number DESC").First(&res).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
 func (c *Context) CreateEBlock(eblock *model.EBlock) error {
 
-	if err := c.db.FirstOrCreate(&eblock).Error; err != nil {
+	// entry list is filled for entry blocks, that were parsed before it was stored
+	assign := model.EBlock{}
+	assign.EntryList = eblock.EntryList
+
+	if err := c.db.Assign(assign).FirstOrCreate(&eblock).Error; err != nil {
+		return err
+	}
+	return nil
+
+}
+
+// SaveEBlock overwrites header & entry list of entry block
+func (c *Context) SaveEBlock(eblock *model.EBlock) error {
+
+	if err := c.db.Save(&eblock).Error; err != nil {
 		return err
 	}
 	return nil
@@ -321,18 +564,325 @@ func (c *Context) UpdateChainsWhere(sql string, chain *model.Chain) error {
 
 }
 
+// SetChainSyncPriority updates sync priority of chain (even with zero value)
+func (c *Context) SetChainSyncPriority(chain *model.Chain, priority int) error {
+
+	if c.db.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Update("sync_priority", priority).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating chain failed")
+
+}
+
+// DeleteChainData deletes all entry blocks & fetched entries of chain (with their receipts) from local DB
+// and resets sync params of chain, so it's synced from scratch till chain.LatestEntryBlock.
+// Entries, that are not completed yet (i.e. written via API & waiting for Factom), are kept.
+func (c *Context) DeleteChainData(chain *model.Chain) error {
+
+	return c.WithTx(func(tx Store) error {
+
+		db := tx.(*Context).db
+
+		// chain row is updated first, so it's locked before data is deleted
+		// & entry blocks stored concurrently by worker are deleted too
+		reset := map[string]interface{}{
+			"synced":               false,
+			"earliest_entry_block": "",
+			"latest_entry_block":   chain.LatestEntryBlock,
+			"worker_id":            -1,
+			"sent_to_pool":         false,
+			"sync_started_at":      nil,
+			"sync_start_sequence":  -1,
+		}
+
+		if err := db.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Updates(reset).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec("DELETE FROM entries_e_blocks WHERE e_block_key_mr IN (SELECT key_mr FROM e_blocks WHERE chain_id = ?)", chain.ChainID).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec("DELETE FROM receipts WHERE entry_hash IN (SELECT entry_hash FROM entries WHERE chain_id = ? AND status = ?)", chain.ChainID, model.EntryCompleted).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec("DELETE FROM entries WHERE chain_id = ? AND status = ?", chain.ChainID, model.EntryCompleted).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec("DELETE FROM e_blocks WHERE chain_id = ?", chain.ChainID).Error; err != nil {
+			return err
+		}
+
+		return nil
+
+	})
+
+}
+
+// RollbackChain deletes entry blocks of chain after ancestor & entries fetched only from them (with their receipts)
+// from local DB and sets chain.LatestEntryBlock to ancestor.
+// Entries, that are not completed yet (i.e. written via API & waiting for Factom), are kept.
+func (c *Context) RollbackChain(chain *model.Chain, ancestor *model.EBlock) error {
+
+	return c.WithTx(func(tx Store) error {
+
+		db := tx.(*Context).db
+
+		eblocks := "SELECT key_mr FROM e_blocks WHERE chain_id = ? AND block_sequence_number > ?"
+
+		var hashes []string
+		if err := db.Table("entries_e_blocks").Where("e_block_key_mr IN ("+eblocks+")", chain.ChainID, ancestor.BlockSequenceNumber).Pluck("entry_entry_hash", &hashes).Error; err != nil {
+			return err
+		}
+
+		if err := db.Exec("DELETE FROM entries_e_blocks WHERE e_block_key_mr IN ("+eblocks+")", chain.ChainID, ancestor.BlockSequenceNumber).Error; err != nil {
+			return err
+		}
+
+		if len(hashes) > 0 {
+
+			// the same entry may be included into entry blocks, that are kept
+			unbound := "SELECT entry_hash FROM entries WHERE entry_hash IN (?) AND status = ? AND NOT EXISTS (SELECT 1 FROM entries_e_blocks WHERE entry_entry_hash = entries.entry_hash)"
+
+			if err := db.Exec("DELETE FROM receipts WHERE entry_hash IN ("+unbound+")", hashes, model.EntryCompleted).Error; err != nil {
+				return err
+			}
+
+			if err := db.Exec("DELETE FROM entries WHERE entry_hash IN ("+unbound+")", hashes, model.EntryCompleted).Error; err != nil {
+				return err
+			}
+
+		}
+
+		if err := db.Exec("DELETE FROM e_blocks WHERE chain_id = ? AND block_sequence_number > ?", chain.ChainID, ancestor.BlockSequenceNumber).Error; err != nil {
+			return err
+		}
+
+		if err := db.Model(&model.Chain{}).Where("chain_id = ?", chain.ChainID).Update("latest_entry_block", ancestor.KeyMR).Error; err != nil {
+			return err
+		}
+
+		return nil
+
+	})
+
+}
+
 func (c *Context) BindChainToUser(chain *model.Chain, user *model.User) error {
 
 	c.db.Model(user).Association("Chains").Append(chain)
 
+	// chain is tracked again
+	c.db.Exec("UPDATE chains SET untracked_at = NULL WHERE chain_id = ? AND untracked_at IS NOT NULL", chain.ChainID)
+
 	return nil
 
 }
 
+// UnbindChainFromUser removes binding of chain to user.
+// If chain is not bound to any user anymore, it's marked as untracked.
+func (c *Context) UnbindChainFromUser(chain *model.Chain, user *model.User) error {
+
+	if err := c.db.Model(user).Association("Chains").Delete(chain).Error; err != nil {
+		return err
+	}
+
+	return c.db.Exec(`UPDATE chains SET untracked_at = NOW() WHERE chain_id = ?
+		AND NOT EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id)`, chain.ChainID).Error
+
+}
+
+// untrackedChainWhere selects chains, that are not bound to any user since before, are not being synced
+// & are not referenced by queued writes, that are not confirmed on Factom yet.
+// Chains, that were never bound to any user (e.g. imported ones), are untracked since their creation.
+const untrackedChainWhere = `COALESCE(chains.untracked_at, chains.created_at) < ? AND chains.worker_id <= 0 AND chains.sent_to_pool IS FALSE
+	AND NOT EXISTS (SELECT 1 FROM users_chains WHERE users_chains.chain_chain_id = chains.chain_id)
+	AND NOT EXISTS (SELECT 1 FROM sync_jobs WHERE sync_jobs.chain_id = chains.chain_id AND sync_jobs.state IN (?))
+	AND NOT EXISTS (SELECT 1 FROM queue WHERE queue.deleted_at IS NULL AND convert_from(queue.params, 'UTF8')::json->>'ChainID' = chains.chain_id)`
+
+// GetUntrackedChains returns chains, that are not bound to any user since before & can be deleted from local DB
+func (c *Context) GetUntrackedChains(before time.Time) []*model.Chain {
+
+	res := []*model.Chain{}
+	c.db.Where(untrackedChainWhere, before, []string{model.SyncJobPending, model.SyncJobRunning}).Find(&res)
+	return res
+
+}
+
+// DeleteUntrackedChain deletes chain with all its entry blocks, entries, receipts, sync job & bindings from local DB.
+// Chain row is locked & checked again inside transaction, so chain is skipped (false returned),
+// if it was bound to user, sent to sync or written since GetUntrackedChains()
+func (c *Context) DeleteUntrackedChain(chain *model.Chain, before time.Time) (bool, error) {
+
+	deleted := false
+
+	err := c.WithTx(func(tx Store) error {
+
+		db := tx.(*Context).db
+
+		var eligible []string
+		err := db.Table("chains").Set("gorm:query_option", "FOR UPDATE").
+			Where("chain_id = ?", chain.ChainID).
+			Where(untrackedChainWhere, before, []string{model.SyncJobPending, model.SyncJobRunning}).
+			Pluck("chain_id", &eligible).Error
+		if err != nil {
+			return err
+		}
+		if len(eligible) == 0 {
+			return nil
+		}
+
+		queries := []string{
+			"DELETE FROM entries_e_blocks WHERE e_block_key_mr IN (SELECT key_mr FROM e_blocks WHERE chain_id = ?)",
+			"DELETE FROM receipts WHERE entry_hash IN (SELECT entry_hash FROM entries WHERE chain_id = ?)",
+			"DELETE FROM entries WHERE chain_id = ?",
+			"DELETE FROM e_blocks WHERE chain_id = ?",
+			"DELETE FROM sync_jobs WHERE chain_id = ?",
+			"DELETE FROM users_chains WHERE chain_chain_id = ?",
+			"DELETE FROM chains WHERE chain_id = ?",
+		}
+
+		for _, query := range queries {
+			if err := db.Exec(query, chain.ChainID).Error; err != nil {
+				return err
+			}
+		}
+
+		deleted = true
+
+		return nil
+
+	})
+
+	return deleted && err == nil, err
+
+}
+
 func (c *Context) BindEntryToEBlock(entry *model.Entry, eblock *model.EBlock) error {
 
-	c.db.Model(eblock).Association("Entries").Append(entry)
+	return c.db.Model(eblock).Association("Entries").Append(entry).Error
+
+}
+
+// CreateEBlockWithEntries writes entry block, its entries & bindings in a single transaction.
+// Entries are written in the order they are provided.
+func (c *Context) CreateEBlockWithEntries(eblock *model.EBlock, entries []*model.Entry) error {
+
+	return c.WithTx(func(tx Store) error {
+		if err := tx.CreateEBlock(eblock); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := tx.CreateEntry(entry); err != nil {
+				return err
+			}
+			if err := tx.BindEntryToEBlock(entry, eblock); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+}
+
+func (c *Context) GetDBlock(dblock *model.DBlock) *model.DBlock {
+
+	res := &model.DBlock{}
+	if c.db.First(&res, dblock).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// GetLatestDBlock returns directory block with max height processed by updates parser
+func (c *Context) GetLatestDBlock() *model.DBlock {
+
+	res := &model.DBlock{}
+	if c.db.Order("height DESC").First(&res).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+func (c *Context) SaveDBlock(dblock *model.DBlock) error {
+
+	if err := c.db.Save(&dblock).Error; err != nil {
+		return err
+	}
+	return nil
+
+}
+
+// DeleteDBlocksAfter deletes processed directory blocks with height > height
+func (c *Context) DeleteDBlocksAfter(height int64) error {
+
+	return c.db.Where("height > ?", height).Delete(&model.DBlock{}).Error
+
+}
+
+func (c *Context) GetSyncJob(job *model.SyncJob) *model.SyncJob {
+
+	res := &model.SyncJob{}
+	if c.db.First(&res, job).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+// CreateMissingSyncJobs creates pending sync jobs for all unsynced chains, that don't have sync job yet
+func (c *Context) CreateMissingSyncJobs() error {
+
+	return c.db.Exec(`INSERT INTO sync_jobs(chain_id, state, attempts, created_at, updated_at)
+		SELECT chain_id, ?, 0, NOW(), NOW() FROM chains
+		WHERE synced IS FALSE AND deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM sync_jobs WHERE sync_jobs.chain_id = chains.chain_id)`, model.SyncJobPending).Error
+
+}
+
+// RestartDoneSyncJobs sets done sync jobs of chains, that are not synced anymore (e.g. rolled back), back to pending
+func (c *Context) RestartDoneSyncJobs() error {
+
+	return c.db.Exec(`UPDATE sync_jobs SET state = ?, attempts = 0, last_error = '', size_estimate = -1, updated_at = NOW()
+		WHERE state = ? AND chain_id IN (SELECT chain_id FROM chains WHERE synced IS FALSE AND deleted_at IS NULL)`, model.SyncJobPending, model.SyncJobDone).Error
+
+}
+
+// TransitSyncJob updates fields (column → value) of sync job, if its current state is one of from.
+// If chainID is empty, all sync jobs in states from are updated.
+func (c *Context) TransitSyncJob(chainID string, from []string, fields map[string]interface{}) error {
+
+	db := c.db.Model(&model.SyncJob{}).Where("state IN (?)", from)
+	if chainID == "" {
+		return db.Updates(fields).Error
+	}
+
+	if db.Where("chain_id = ?", chainID).Updates(fields).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Sync job of chain %s not found or its state is not one of %v", chainID, from)
+
+}
+
+func (c *Context) GetReceipt(receipt *model.Receipt) *model.Receipt {
+
+	res := &model.Receipt{}
+	if c.db.First(&res, receipt).RecordNotFound() {
+		return nil
+	}
+	return res
+
+}
+
+func (c *Context) SaveReceipt(receipt *model.Receipt) error {
 
+	if err := c.db.Save(&receipt).Error; err != nil {
+		return err
+	}
 	return nil
 
 }
@@ -382,6 +932,42 @@ func (c *Context) UpdateQueue(queue *model.Queue) error {
 
 }
 
+// UpdateQueueFields updates fields (column → value) of queue item, including zero values
+func (c *Context) UpdateQueueFields(queue *model.Queue, fields map[string]interface{}) error {
+
+	if c.db.Model(&model.Queue{}).Where("id = ?", queue.ID).Updates(fields).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating queue failed")
+
+}
+
+// GetECSpending returns EC spent by commits of queue tasks (including deleted ones) per EC address
+func (c *Context) GetECSpending() map[string]int64 {
+
+	res := make(map[string]int64)
+
+	rows, err := c.db.Raw("SELECT ec_address, SUM(ec_cost) FROM queue WHERE ec_address IS NOT NULL AND ec_address <> '' GROUP BY ec_address").Rows()
+	if err != nil {
+		log.Error(err)
+		return res
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var address string
+		var spent int64
+		if err := rows.Scan(&address, &spent); err != nil {
+			log.Error(err)
+			return res
+		}
+		res[address] = spent
+	}
+
+	return res
+
+}
+
 func (c *Context) DeleteQueue(queue *model.Queue) error {
 
 	if c.db.Delete(&queue).RowsAffected > 0 {
@@ -390,3 +976,60 @@ func (c *Context) DeleteQueue(queue *model.Queue) error {
 	return fmt.Errorf("DB: Deletion queue failed")
 
 }
+
+func (c *Context) CreateECPurchase(purchase *model.ECPurchase) error {
+
+	if c.db.Create(&purchase).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Creating EC purchase failed")
+
+}
+
+// UpdateECPurchase updates status & error of EC purchase
+func (c *Context) UpdateECPurchase(purchase *model.ECPurchase) error {
+
+	if c.db.Model(&purchase).Updates(map[string]interface{}{"status": purchase.Status, "error": purchase.Error}).RowsAffected > 0 {
+		return nil
+	}
+	return fmt.Errorf("DB: Updating EC purchase failed")
+
+}
+
+// GetECPurchases returns latest records of EC purchases audit log
+func (c *Context) GetECPurchases(limit int) []*model.ECPurchase {
+
+	res := []*model.ECPurchase{}
+	c.db.Order("created_at desc").Limit(limit).Find(&res)
+
+	return res
+
+}
+
+// GetLatestECPurchase returns the latest EC purchase (submitted or failed) for EC address
+func (c *Context) GetLatestECPurchase(ecAddress string) *model.ECPurchase {
+
+	res := &model.ECPurchase{}
+	if c.db.Where("ec_address = ?", ecAddress).Order("created_at desc").First(&res).RecordNotFound() {
+		return nil
+	}
+
+	return res
+
+}
+
+// GetECPurchasedSince returns amount of EC purchased by submitted or confirmed transactions after since
+func (c *Context) GetECPurchasedSince(since time.Time) (int64, error) {
+
+	var res struct {
+		Total int64
+	}
+
+	err := c.db.Raw("SELECT COALESCE(SUM(ec_amount), 0) AS total FROM ec_purchases WHERE status IN (?) AND created_at > ?", []string{model.ECPurchaseSubmitted, model.ECPurchaseConfirmed}, since).Scan(&res).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Total, nil
+
+}
@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"github.com/FactomProject/factom"
+	log "github.com/sirupsen/logrus"
+	"time"
+)
+
+const (
+	// cached balance of EC address is fetched again after this time, if balance monitor is disabled
+	BalanceCacheTTL = 1 * time.Minute
+)
+
+// cachedBalance is balance of EC address fetched from factomd, decremented by commits since fetchedAt
+type cachedBalance struct {
+	balance   int64
+	fetchedAt time.Time
+}
+
+// RefreshBalances fetches balances of server EC addresses from factomd into cache.
+// If balance of some address can not be fetched, its cached balance is kept.
+func (c *Context) RefreshBalances() error {
+
+	var lastErr error
+
+	for _, signer := range c.signers {
+		balance, err := factom.GetECBalance(signer.PubString())
+		if err != nil {
+			log.Error("Can not fetch balance of EC address ", signer.PubString(), ": ", err)
+			lastErr = err
+			continue
+		}
+		c.balanceMu.Lock()
+		c.balances[signer.PubString()] = cachedBalance{balance: balance, fetchedAt: time.Now()}
+		c.balanceMu.Unlock()
+	}
+
+	c.balanceMu.Lock()
+	c.refreshedAt = time.Now()
+	c.balanceMu.Unlock()
+
+	return lastErr
+
+}
+
+// GetBalance returns cached balance of server EC address
+func (c *Context) GetBalance(address string) int64 {
+
+	return c.balance(address)
+
+}
+
+// BalancesRefreshedAt returns time of the latest balances refresh
+func (c *Context) BalancesRefreshedAt() time.Time {
+
+	c.balanceMu.RLock()
+	defer c.balanceMu.RUnlock()
+
+	return c.refreshedAt
+
+}
+
+// balance returns cached balance of EC address.
+// Balance is fetched from factomd, if not cached yet or cached longer than balanceTTL (i.e. balance monitor is disabled or failing).
+func (c *Context) balance(address string) int64 {
+
+	c.balanceMu.RLock()
+	cached, ok := c.balances[address]
+	c.balanceMu.RUnlock()
+
+	if ok && time.Since(cached.fetchedAt) < c.balanceTTL {
+		return cached.balance
+	}
+
+	balance, err := factom.GetECBalance(address)
+	if err != nil {
+		log.Error(err)
+		if ok {
+			return cached.balance
+		}
+		return 0
+	}
+
+	c.balanceMu.Lock()
+	c.balances[address] = cachedBalance{balance: balance, fetchedAt: time.Now()}
+	c.balanceMu.Unlock()
+
+	return balance
+
+}
+
+// spend decrements cached balance of server EC address by cost of commit until the next refresh.
+// Addresses of users are not cached, so they are not touched.
+func (c *Context) spend(address string, cost int64) {
+
+	c.balanceMu.Lock()
+	defer c.balanceMu.Unlock()
+
+	if cached, ok := c.balances[address]; ok {
+		cached.balance -= cost
+		c.balances[address] = cached
+	}
+
+}
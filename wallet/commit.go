@@ -0,0 +1,128 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/FactomProject/factom"
+	"time"
+)
+
+// composeEntryCommit creates commit-entry request the same way factom.ComposeEntryCommit() does,
+// but the message is signed by signer
+func composeEntryCommit(e *factom.Entry, signer Signer) (*factom.JSON2Request, error) {
+
+	buf := new(bytes.Buffer)
+
+	// 1 byte version
+	buf.Write([]byte{0})
+
+	// 6 byte milliTimestamp (truncated unix time)
+	buf.Write(milliTime())
+
+	// 32 byte Entry Hash
+	buf.Write(e.Hash())
+
+	// 1 byte number of entry credits to pay
+	cost, err := factom.EntryCost(e)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(byte(cost))
+
+	return signCommit("commit-entry", buf, signer)
+
+}
+
+// composeChainCommit creates commit-chain request the same way factom.ComposeChainCommit() does,
+// but the message is signed by signer
+func composeChainCommit(c *factom.Chain, signer Signer) (*factom.JSON2Request, error) {
+
+	buf := new(bytes.Buffer)
+
+	// 1 byte version
+	buf.Write([]byte{0})
+
+	// 6 byte milliTimestamp
+	buf.Write(milliTime())
+
+	e := c.FirstEntry
+
+	cid, err := hex.DecodeString(c.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 32 byte ChainID Hash
+	buf.Write(shad(cid))
+
+	// 32 byte Weld; sha256(sha256(EntryHash + ChainID))
+	buf.Write(shad(append(e.Hash(), cid...)))
+
+	// 32 byte Entry Hash of the First Entry
+	buf.Write(e.Hash())
+
+	// 1 byte number of Entry Credits to pay
+	cost, err := factom.EntryCost(e)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(byte(cost + ChainECCost))
+
+	return signCommit("commit-chain", buf, signer)
+
+}
+
+// signCommit appends 32 byte Entry Credit Address Public Key + 64 byte Signature to commit message
+func signCommit(method string, buf *bytes.Buffer, signer Signer) (*factom.JSON2Request, error) {
+
+	pub, sig, err := signer.Sign(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(pub)
+	buf.Write(sig)
+
+	params := map[string]string{"message": hex.EncodeToString(buf.Bytes())}
+
+	return factom.NewJSON2Request(method, factom.APICounter(), params), nil
+
+}
+
+// sendCommit sends commit request to factomd & returns txid
+func sendCommit(req *factom.JSON2Request) (string, error) {
+
+	resp, err := factom.SendFactomdRequest(req)
+	if err != nil {
+		return "", err
+	}
+	if resp.Error != nil {
+		return "", resp.Error
+	}
+
+	res := struct {
+		TxID string `json:"txid"`
+	}{}
+	if err := json.Unmarshal(resp.JSONResult(), &res); err != nil {
+		return "", err
+	}
+
+	return res.TxID, nil
+
+}
+
+func milliTime() []byte {
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.BigEndian, time.Now().UnixNano()/1e6)
+	return buf.Bytes()[2:]
+}
+
+// shad Double Sha256 Hash; sha256(sha256(data))
+func shad(data []byte) []byte {
+	h1 := sha256.Sum256(data)
+	h2 := sha256.Sum256(h1[:])
+	return h2[:]
+}
@@ -0,0 +1,115 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/DeFacto-Team/Factom-Open-API/config"
+	"github.com/FactomProject/factom"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"sync"
+)
+
+const (
+	// scrypt N of user Es addresses encryption is lower than keystore one, as user's key is derived at runtime
+	userScryptN = 1 << 15
+	saltSize    = 16
+)
+
+// userCiphers caches ciphers of user Es addresses by salt, so key is derived by scrypt once per address
+var userCiphers sync.Map
+
+// EncryptEsAddress validates Es address & encrypts it with AES-GCM using key derived from encryption key set in config
+// by scrypt with random salt. Salt & nonce are stored before ciphertext.
+func EncryptEsAddress(conf *config.Config, esAddress string) (string, error) {
+
+	if _, err := factom.GetECAddress(esAddress); err != nil {
+		return "", fmt.Errorf("INVALID Es address: %s", err)
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(conf, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(append(salt, nonce...), nonce, []byte(esAddress), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+
+}
+
+// DecryptEsAddress decrypts Es address encrypted by EncryptEsAddress & returns EC address
+func DecryptEsAddress(conf *config.Config, encrypted string) (*factom.ECAddress, error) {
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < saltSize {
+		return nil, fmt.Errorf("Encrypted Es address is malformed")
+	}
+
+	gcm, err := newGCM(conf, sealed[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+
+	sealed = sealed[saltSize:]
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("Encrypted Es address is malformed")
+	}
+
+	esAddress, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Can not decrypt Es address, check wallet encryption key")
+	}
+
+	return factom.GetECAddress(string(esAddress))
+
+}
+
+// newGCM creates AES-256-GCM cipher with key derived from wallet encryption key set in config & salt by scrypt
+func newGCM(conf *config.Config, salt []byte) (cipher.AEAD, error) {
+
+	if conf.Wallet.EncryptionKey == "" {
+		return nil, fmt.Errorf("Wallet encryption key is not set in config")
+	}
+
+	if gcm, ok := userCiphers.Load(string(salt)); ok {
+		return gcm.(cipher.AEAD), nil
+	}
+
+	key, err := scrypt.Key([]byte(conf.Wallet.EncryptionKey), salt, userScryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	userCiphers.Store(string(salt), gcm)
+
+	return gcm, nil
+
+}
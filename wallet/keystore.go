@@ -0,0 +1,334 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/FactomProject/factom"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh/terminal"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// passphrase of keystore is read from this environment variable, if set
+	KeystorePassphraseEnv = "FOA_WALLET_PASSPHRASE"
+
+	keystoreVersion = 1
+
+	// scrypt params of new keys
+	scryptN      = 1 << 18
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+var errKeystorePassphrase = fmt.Errorf("Keystore: Can not decrypt key, wrong passphrase")
+
+// Keystore is file with Es addresses encrypted by passphrase
+type Keystore struct {
+	Version int            `json:"version"`
+	Keys    []*KeystoreKey `json:"keys"`
+}
+
+// KeystoreKey is Es address encrypted with AES-256-GCM & key derived from passphrase by scrypt
+type KeystoreKey struct {
+	// public EC address, stored in plain text to be exported without passphrase
+	Address    string `json:"address"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// LoadKeystore decrypts all keys of keystore file with passphrase
+func LoadKeystore(path string, passphrase string) ([]*factom.ECAddress, error) {
+
+	ks, err := readKeystore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var addresses []*factom.ECAddress
+
+	for _, key := range ks.Keys {
+		ec, err := key.decrypt(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, ec)
+	}
+
+	return addresses, nil
+
+}
+
+// ImportToKeystore encrypts Es address with passphrase & adds it into keystore file.
+// Keystore file is created if it doesn't exist, passphrase must match passphrase of existing keys.
+// Returns public EC address of imported key.
+func ImportToKeystore(path string, passphrase string, esAddress string) (string, error) {
+
+	if passphrase == "" {
+		return "", fmt.Errorf("Keystore: Passphrase can not be empty")
+	}
+
+	ec, err := factom.GetECAddress(esAddress)
+	if err != nil {
+		return "", fmt.Errorf("Keystore: INVALID Es address")
+	}
+
+	ks := &Keystore{Version: keystoreVersion}
+	if _, err := os.Stat(path); err == nil {
+		if ks, err = readKeystore(path); err != nil {
+			return "", err
+		}
+	}
+
+	for _, key := range ks.Keys {
+		if _, err := key.decrypt(passphrase); err != nil {
+			return "", err
+		}
+		if key.Address == ec.PubString() {
+			return "", fmt.Errorf("Keystore: EC address %s already exists", ec.PubString())
+		}
+	}
+
+	key, err := encryptKey(ec, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	ks.Keys = append(ks.Keys, key)
+
+	if err := writeKeystore(path, ks); err != nil {
+		return "", err
+	}
+
+	return ec.PubString(), nil
+
+}
+
+// KeystoreAddresses returns public EC addresses of keystore file, passphrase is not needed
+func KeystoreAddresses(path string) ([]string, error) {
+
+	ks, err := readKeystore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, len(ks.Keys))
+	for i, key := range ks.Keys {
+		addresses[i] = key.Address
+	}
+
+	return addresses, nil
+
+}
+
+// ChangeKeystorePassphrase re-encrypts all keys of keystore file with new passphrase
+func ChangeKeystorePassphrase(path string, oldPassphrase string, newPassphrase string) error {
+
+	if newPassphrase == "" {
+		return fmt.Errorf("Keystore: New passphrase can not be empty")
+	}
+
+	addresses, err := LoadKeystore(path, oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	ks := &Keystore{Version: keystoreVersion}
+
+	for _, ec := range addresses {
+		key, err := encryptKey(ec, newPassphrase)
+		if err != nil {
+			return err
+		}
+		ks.Keys = append(ks.Keys, key)
+	}
+
+	return writeKeystore(path, ks)
+
+}
+
+// ReadPassphrase returns keystore passphrase from environment variable,
+// or prompts it from terminal if variable is not set
+func ReadPassphrase(prompt string) (string, error) {
+
+	if passphrase, ok := os.LookupEnv(KeystorePassphraseEnv); ok {
+		return passphrase, nil
+	}
+
+	passphrase, err := PromptSecret(prompt)
+	if err != nil {
+		return "", fmt.Errorf("Keystore: %s is not set & %s", KeystorePassphraseEnv, err.Error())
+	}
+
+	return passphrase, nil
+
+}
+
+// PromptSecret reads passphrase or private key from terminal without echo
+func PromptSecret(prompt string) (string, error) {
+
+	fd := int(os.Stdin.Fd())
+	if !terminal.IsTerminal(fd) {
+		return "", fmt.Errorf("stdin is not a terminal")
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := terminal.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	return string(passphrase), nil
+
+}
+
+func encryptKey(ec *factom.ECAddress, passphrase string) (*KeystoreKey, error) {
+
+	key := &KeystoreKey{Address: ec.PubString(), KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP}
+
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := key.newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	// public address is authenticated, so it can't be swapped in file
+	ciphertext := gcm.Seal(nil, nonce, []byte(ec.SecString()), []byte(key.Address))
+
+	key.Salt = hex.EncodeToString(salt)
+	key.Nonce = hex.EncodeToString(nonce)
+	key.Ciphertext = hex.EncodeToString(ciphertext)
+
+	return key, nil
+
+}
+
+func (key *KeystoreKey) decrypt(passphrase string) (*factom.ECAddress, error) {
+
+	if key.KDF != "scrypt" {
+		return nil, fmt.Errorf("Keystore: Unsupported KDF %s of key %s", key.KDF, key.Address)
+	}
+
+	salt, err := hex.DecodeString(key.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("Keystore: Malformed salt of key %s", key.Address)
+	}
+
+	nonce, err := hex.DecodeString(key.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("Keystore: Malformed nonce of key %s", key.Address)
+	}
+
+	ciphertext, err := hex.DecodeString(key.Ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("Keystore: Malformed ciphertext of key %s", key.Address)
+	}
+
+	gcm, err := key.newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("Keystore: Malformed nonce of key %s", key.Address)
+	}
+
+	esAddress, err := gcm.Open(nil, nonce, ciphertext, []byte(key.Address))
+	if err != nil {
+		return nil, errKeystorePassphrase
+	}
+
+	ec, err := factom.GetECAddress(string(esAddress))
+	if err != nil || ec.PubString() != key.Address {
+		return nil, fmt.Errorf("Keystore: Key %s is corrupted", key.Address)
+	}
+
+	return ec, nil
+
+}
+
+// newGCM creates AES-256-GCM cipher with key derived from passphrase by scrypt
+func (key *KeystoreKey) newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+
+	derived, err := scrypt.Key([]byte(passphrase), salt, key.N, key.R, key.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("Keystore: %s", err)
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+
+}
+
+func readKeystore(path string) (*Keystore, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Keystore: %s", err)
+	}
+
+	ks := &Keystore{}
+	if err := json.Unmarshal(data, ks); err != nil {
+		return nil, fmt.Errorf("Keystore: Malformed file %s", path)
+	}
+
+	if ks.Version != keystoreVersion {
+		return nil, fmt.Errorf("Keystore: Unsupported version %d", ks.Version)
+	}
+
+	return ks, nil
+
+}
+
+// writeKeystore writes keystore into temporary file & renames it, so keystore is never left half-written
+func writeKeystore(path string, ks *Keystore) error {
+
+	data, err := json.MarshalIndent(ks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("Keystore: %s", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("Keystore: %s", err)
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("Keystore: %s", err)
+	}
+
+	return nil
+
+}
@@ -0,0 +1,176 @@
+package wallet
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/FactomProject/btcutil/base58"
+	"github.com/FactomProject/ed25519"
+	"github.com/FactomProject/factom"
+	"strings"
+)
+
+// Purchase is result of successful FCT→EC conversion
+type Purchase struct {
+	TxID string
+	// public Factoid address paid for EC
+	FCTAddress string
+	ECAddress  string
+	ECAmount   int64
+	// factoshis converted into EC & paid as fee
+	Factoshis int64
+	Fee       int64
+	// factoshis per EC
+	Rate int64
+}
+
+// ValidateFsAddress returns public Factoid address of Fs address, Fs address is never echoed into error
+func ValidateFsAddress(fsAddress string) (string, error) {
+
+	fct, err := factom.GetFactoidAddress(fsAddress)
+	if err != nil {
+		return "", fmt.Errorf("INVALID Fs address set in config")
+	}
+
+	return fct.String(), nil
+
+}
+
+// PurchaseEC builds FCT→EC conversion transaction of ecAmount EC paid from Fs address,
+// signs it locally & submits it to factomd.
+// If submitting fails, purchase with locally computed TxID is returned together with error,
+// as factomd may still have accepted the transaction (e.g. response timed out).
+func PurchaseEC(fsAddress string, ecAddress string, ecAmount int64) (*Purchase, error) {
+
+	fct, err := factom.GetFactoidAddress(fsAddress)
+	if err != nil {
+		return nil, fmt.Errorf("INVALID Fs address set in config")
+	}
+
+	if !factom.IsValidAddress(ecAddress) || !strings.HasPrefix(ecAddress, "EC") {
+		return nil, fmt.Errorf("INVALID EC address: %s", ecAddress)
+	}
+
+	if ecAmount <= 0 {
+		return nil, fmt.Errorf("EC amount must be positive")
+	}
+
+	rate, err := factom.GetRate()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Purchase{FCTAddress: fct.String(), ECAddress: ecAddress, ECAmount: ecAmount, Rate: int64(rate)}
+	output := uint64(ecAmount) * rate
+
+	balance, err := factom.GetFactoidBalance(p.FCTAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// fee depends on size of transaction, which depends on input amount, so it's calculated until it's stable
+	var tx []byte
+	var txid string
+	var fee uint64
+	for {
+		tx, txid = composeECPurchase(fct, ecAddress, output+fee, output)
+		next := transactionFee(len(tx), rate)
+		if next <= fee {
+			break
+		}
+		fee = next
+	}
+
+	p.Fee = int64(fee)
+	p.Factoshis = int64(output + fee)
+
+	if balance < p.Factoshis {
+		return nil, fmt.Errorf("Factoid address %s balance %s FCT is not enough to buy %d EC", p.FCTAddress, factom.FactoshiToFactoid(uint64(balance)), ecAmount)
+	}
+
+	p.TxID = txid
+
+	params := map[string]string{"transaction": hex.EncodeToString(tx)}
+	resp, err := factom.SendFactomdRequest(factom.NewJSON2Request("factoid-submit", factom.APICounter(), params))
+	if err != nil {
+		return p, err
+	}
+	if resp.Error != nil {
+		return p, resp.Error
+	}
+
+	res := struct {
+		TxID string `json:"txid"`
+	}{}
+	if err := json.Unmarshal(resp.JSONResult(), &res); err != nil {
+		return p, err
+	}
+
+	if res.TxID != "" {
+		p.TxID = res.TxID
+	}
+
+	return p, nil
+
+}
+
+// composeECPurchase creates signed factoid transaction with one input & one EC output & returns it with its txid
+func composeECPurchase(fct *factom.FactoidAddress, ecAddress string, input uint64, output uint64) ([]byte, string) {
+
+	buf := new(bytes.Buffer)
+
+	// varint version
+	buf.Write(varInt(2))
+
+	// 6 byte milliTimestamp
+	buf.Write(milliTime())
+
+	// 1 byte number of inputs, factoid outputs & EC outputs
+	buf.Write([]byte{1, 0, 1})
+
+	// input: varint amount + 32 byte RCD hash
+	buf.Write(varInt(input))
+	buf.Write(fct.RCDHash())
+
+	// EC output: varint amount + 32 byte EC public key
+	buf.Write(varInt(output))
+	buf.Write(base58.Decode(ecAddress)[2:34])
+
+	// signature covers the transaction without RCDs & signatures, txid is hash of the same data
+	sig := ed25519.Sign(fct.SecFixed(), buf.Bytes())
+	txid := sha256.Sum256(buf.Bytes())
+
+	// RCD type 1: 1 byte type + 32 byte public key, followed by 64 byte signature
+	buf.WriteByte(1)
+	buf.Write(fct.PubBytes())
+	buf.Write(sig[:])
+
+	return buf.Bytes(), hex.EncodeToString(txid[:])
+
+}
+
+// transactionFee returns fee of transaction with one input & one EC output in factoshis:
+// 1 EC per started KiB of transaction, 10 EC per signature & 1 EC per input & output
+func transactionFee(size int, rate uint64) uint64 {
+
+	fee := uint64(size+1023) / 1024
+	fee += 10
+	fee += 2
+
+	return fee * rate
+
+}
+
+// varInt encodes unsigned integer the way factomd does: big-endian 7 bit groups, high bit is set on all groups but the last one
+func varInt(v uint64) []byte {
+
+	groups := []byte{byte(v & 0x7F)}
+	for v >>= 7; v > 0; v >>= 7 {
+		groups = append([]byte{byte(v&0x7F) | 0x80}, groups...)
+	}
+
+	return groups
+
+}
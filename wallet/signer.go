@@ -0,0 +1,211 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/FactomProject/factom"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	// signer backends
+	SignerLocal   = "local"
+	SignerWalletd = "walletd"
+	SignerHTTP    = "http"
+)
+
+// Signer signs commit messages with private key of EC address
+type Signer interface {
+	// PubString returns public EC address
+	PubString() string
+	// Sign returns 32 byte public key & 64 byte ed25519 signature of msg
+	Sign(msg []byte) ([]byte, []byte, error)
+}
+
+// LocalSigner signs with Es address kept in memory
+type LocalSigner struct {
+	ec *factom.ECAddress
+}
+
+func NewLocalSigner(ec *factom.ECAddress) *LocalSigner {
+	return &LocalSigner{ec: ec}
+}
+
+func (s *LocalSigner) PubString() string {
+	return s.ec.PubString()
+}
+
+func (s *LocalSigner) Sign(msg []byte) ([]byte, []byte, error) {
+	return s.ec.PubBytes(), s.ec.Sign(msg)[:], nil
+}
+
+// WalletdSigner signs with factom-walletd "sign-data" JSON-RPC method, Es address is kept by walletd
+type WalletdSigner struct {
+	address  string
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+func NewWalletdSigner(address string, url string, user string, password string) *WalletdSigner {
+	return &WalletdSigner{address: address, url: url, user: user, password: password, client: &http.Client{Timeout: time.Second * 30}}
+}
+
+func (s *WalletdSigner) PubString() string {
+	return s.address
+}
+
+func (s *WalletdSigner) Sign(msg []byte) ([]byte, []byte, error) {
+
+	params := map[string]string{"signer": s.address, "data": base64.StdEncoding.EncodeToString(msg)}
+	req := factom.NewJSON2Request("sign-data", 0, params)
+
+	j, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := s.post(j)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp := factom.NewJSON2Response()
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, nil, err
+	}
+	if resp.Error != nil {
+		return nil, nil, resp.Error
+	}
+
+	res := struct {
+		PubKey    []byte `json:"pubkey"`
+		Signature []byte `json:"signature"`
+	}{}
+	if err := json.Unmarshal(resp.JSONResult(), &res); err != nil {
+		return nil, nil, err
+	}
+
+	return checkSignature(s.address, res.PubKey, res.Signature)
+
+}
+
+func (s *WalletdSigner) post(j []byte) ([]byte, error) {
+
+	re, err := http.NewRequest("POST", s.url+"/v2", bytes.NewBuffer(j))
+	if err != nil {
+		return nil, err
+	}
+
+	re.SetBasicAuth(s.user, s.password)
+	re.Header.Add("Content-Type", "application/json")
+
+	resp, err := s.client.Do(re)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("Walletd username/password incorrect")
+	}
+
+	return ioutil.ReadAll(resp.Body)
+
+}
+
+// HTTPSigner signs with generic HTTP signing service.
+// Request: POST url {"address": "EC...", "data": "<hex>"} with optional bearer token,
+// response: {"publicKey": "<hex>", "signature": "<hex>"}.
+type HTTPSigner struct {
+	address string
+	url     string
+	token   string
+	client  *http.Client
+}
+
+func NewHTTPSigner(address string, url string, token string) *HTTPSigner {
+	return &HTTPSigner{address: address, url: url, token: token, client: &http.Client{Timeout: time.Second * 30}}
+}
+
+func (s *HTTPSigner) PubString() string {
+	return s.address
+}
+
+func (s *HTTPSigner) Sign(msg []byte) ([]byte, []byte, error) {
+
+	j, err := json.Marshal(map[string]string{"address": s.address, "data": hex.EncodeToString(msg)})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	re, err := http.NewRequest("POST", s.url, bytes.NewBuffer(j))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	re.Header.Add("Content-Type", "application/json")
+	if s.token != "" {
+		re.Header.Add("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(re)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Signing service responded with status %d: %s", resp.StatusCode, body)
+	}
+
+	res := struct {
+		PublicKey string `json:"publicKey"`
+		Signature string `json:"signature"`
+	}{}
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, nil, err
+	}
+
+	pub, err := hex.DecodeString(res.PublicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sig, err := hex.DecodeString(res.Signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return checkSignature(s.address, pub, sig)
+
+}
+
+// checkSignature checks that remote signer used the key of EC address
+func checkSignature(address string, pub []byte, sig []byte) ([]byte, []byte, error) {
+
+	if len(pub) != 32 || len(sig) != 64 {
+		return nil, nil, fmt.Errorf("Signer returned malformed public key or signature")
+	}
+
+	ec := &factom.ECAddress{Pub: new([32]byte)}
+	copy(ec.Pub[:], pub)
+
+	if ec.PubString() != address {
+		return nil, nil, fmt.Errorf("Signer returned public key of %s instead of %s", ec.PubString(), address)
+	}
+
+	return pub, sig, nil
+
+}
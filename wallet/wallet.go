@@ -5,48 +5,320 @@ import (
 	"github.com/DeFacto-Team/Factom-Open-API/config"
 	"github.com/FactomProject/factom"
 	log "github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
 	ChainECCost = 10
+
+	// strategies of EC address selection
+	StrategyRoundRobin = "roundrobin"
+	StrategyBalance    = "balance"
+	StrategyUser       = "user"
+
+	// JSON-RPC error of factomd, that rejects commit accepted before
+	RepeatedCommitCode    = -32011
+	RepeatedCommitMessage = "Repeated Commit"
 )
 
 type Wallet interface {
-	GetEC() *factom.ECAddress
-	CommitRevealEntry(entry *factom.Entry) (string, error)
-	CommitRevealChain(chain *factom.Chain) (string, error)
+	GetECAddresses() []string
+	GetBalance(address string) int64
+	RefreshBalances() error
+	BalancesRefreshedAt() time.Time
+	CommitEntry(entry *factom.Entry, userID int, userEC *factom.ECAddress) (*Commit, error)
+	RevealEntry(entry *factom.Entry) (string, error)
+	CommitChain(chain *factom.Chain, userID int, userEC *factom.ECAddress) (*Commit, error)
+	RevealChain(chain *factom.Chain) (string, error)
 }
 
 type Context struct {
-	ec *factom.ECAddress
+	// signers of server EC addresses
+	signers    []Signer
+	strategy   string
+	minBalance int64
+	// round-robin counter
+	next uint32
+	// index of dedicated signer by user ID (user strategy)
+	userSigners map[int]int
+	// use server addresses if user's address is out of EC
+	userFallback bool
+	// cached balances of server EC addresses, decremented by commits & refreshed by balance monitor
+	balanceMu   sync.RWMutex
+	balances    map[string]cachedBalance
+	balanceTTL  time.Duration
+	refreshedAt time.Time
+}
+
+// Commit is result of successful commit
+type Commit struct {
+	TxID string
+	// public EC address paid for the commit
+	ECAddress string
+	Cost      int64
 }
 
 func NewWallet(conf *config.Config) (Wallet, error) {
 
-	// setup EC pub-priv keypair from Es address
-	ECAddress, err := factom.GetECAddress(conf.Factom.EsAddress)
+	c := &Context{strategy: conf.Wallet.Strategy, minBalance: conf.Wallet.MinBalance, userFallback: conf.Wallet.UserFallback, balances: make(map[string]cachedBalance)}
+
+	// without balance monitor, cached balances are fetched again after BalanceCacheTTL
+	c.balanceTTL = BalanceCacheTTL
+	if conf.Wallet.BalanceRefresh > 0 {
+		c.balanceTTL = 2 * time.Duration(conf.Wallet.BalanceRefresh) * time.Second
+	}
+
+	switch c.strategy {
+	case StrategyRoundRobin, StrategyBalance, StrategyUser:
+	default:
+		return nil, fmt.Errorf("INVALID wallet strategy set in config: %s", c.strategy)
+	}
+
+	signers, err := newSigners(conf)
 	if err != nil {
-		return nil, fmt.Errorf("INVALID Es address set in config: %s", conf.Factom.EsAddress)
-	} else {
-		balance, _ := factom.GetECBalance(ECAddress.PubString())
-		log.Info("Using EC address: ", ECAddress, ", balance=", balance)
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+
+	for _, signer := range signers {
+
+		if known[signer.PubString()] {
+			continue
+		}
+		known[signer.PubString()] = true
+
+		balance := c.balance(signer.PubString())
+		log.Info("Using EC address: ", signer.PubString(), ", balance=", balance)
 		if balance == 0 {
 			log.Warn("EC address balance is 0 EC. Please top up your EC address to let API create chains & entries on the blockchain.")
 		}
+
+		c.signers = append(c.signers, signer)
+
+	}
+
+	// without server addresses, only users with own Es addresses can write
+	if len(c.signers) == 0 {
+		if conf.Wallet.EncryptionKey == "" {
+			return nil, fmt.Errorf("No Es address or keystore set in config")
+		}
+		log.Warn("No Es address set in config, only users with own Es addresses are able to write on the blockchain")
+	}
+
+	if c.strategy == StrategyUser {
+		if err := c.setUserSigners(conf.Wallet.UserAddresses); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("Wallet: ", len(c.signers), " EC address(es), signer=", conf.Wallet.Signer, ", strategy=", c.strategy)
+
+	if conf.Wallet.TopUpFsAddress != "" {
+		fctAddress, err := ValidateFsAddress(conf.Wallet.TopUpFsAddress)
+		if err != nil {
+			return nil, err
+		}
+		// purchases are made by balance monitor only
+		if conf.Wallet.BalanceRefresh <= 0 {
+			return nil, fmt.Errorf("Wallet auto top-up requires balance monitor, set wallet balance refresh interval in config")
+		}
+		if conf.Wallet.TopUpTarget <= conf.Wallet.TopUpThreshold {
+			return nil, fmt.Errorf("Wallet top-up target must be greater than top-up threshold")
+		}
+		log.Info("Wallet auto top-up: FCT address=", fctAddress, ", threshold=", conf.Wallet.TopUpThreshold, " EC, target=", conf.Wallet.TopUpTarget, " EC, daily limit=", conf.Wallet.TopUpDailyLimit, " EC")
+	}
+
+	return c, nil
+
+}
+
+// newSigners creates signers of server EC addresses.
+// Local signer uses Es addresses from config & keystore, remote signers use public EC addresses from config.
+func newSigners(conf *config.Config) ([]Signer, error) {
+
+	var signers []Signer
+
+	if conf.Wallet.Signer == SignerLocal {
+
+		esAddresses := conf.Wallet.EsAddresses
+		if conf.Factom.EsAddress != "" {
+			esAddresses = append([]string{conf.Factom.EsAddress}, esAddresses...)
+		}
+
+		for _, esAddress := range esAddresses {
+			// setup EC pub-priv keypair from Es address
+			// Es address is never echoed, as it may be a private key with a typo
+			ECAddress, err := factom.GetECAddress(esAddress)
+			if err != nil {
+				return nil, fmt.Errorf("INVALID Es address set in config")
+			}
+			signers = append(signers, NewLocalSigner(ECAddress))
+		}
+
+		if conf.Wallet.Keystore != "" {
+			passphrase, err := ReadPassphrase("Keystore passphrase: ")
+			if err != nil {
+				return nil, err
+			}
+			addresses, err := LoadKeystore(conf.Wallet.Keystore, passphrase)
+			if err != nil {
+				return nil, err
+			}
+			log.Info("Keystore unlocked: ", len(addresses), " EC address(es)")
+			for _, ECAddress := range addresses {
+				signers = append(signers, NewLocalSigner(ECAddress))
+			}
+		}
+
+		return signers, nil
+
+	}
+
+	// private keys are kept by remote signer only
+	if conf.Factom.EsAddress != "" || len(conf.Wallet.EsAddresses) > 0 || conf.Wallet.Keystore != "" {
+		return nil, fmt.Errorf("Es addresses & keystore must not be set in config with %s signer, set public EC addresses instead", conf.Wallet.Signer)
+	}
+
+	for _, address := range conf.Wallet.ECAddresses {
+
+		if !factom.IsValidAddress(address) || !strings.HasPrefix(address, "EC") {
+			return nil, fmt.Errorf("INVALID EC address set in config: %s", address)
+		}
+
+		switch conf.Wallet.Signer {
+		case SignerWalletd:
+			signers = append(signers, NewWalletdSigner(address, conf.Wallet.SignerURL, conf.Wallet.SignerUser, conf.Wallet.SignerPassword))
+		case SignerHTTP:
+			signers = append(signers, NewHTTPSigner(address, conf.Wallet.SignerURL, conf.Wallet.SignerToken))
+		default:
+			return nil, fmt.Errorf("INVALID wallet signer set in config: %s", conf.Wallet.Signer)
+		}
+
+	}
+
+	return signers, nil
+
+}
+
+// setUserSigners maps users to their dedicated server EC addresses, that must be one of wallet addresses
+func (c *Context) setUserSigners(userAddresses map[int]string) error {
+
+	if len(userAddresses) == 0 {
+		return fmt.Errorf("Wallet user addresses must be set in config with %s strategy", StrategyUser)
+	}
+
+	c.userSigners = make(map[int]int)
+
+	for userID, address := range userAddresses {
+		found := false
+		for i, signer := range c.signers {
+			if signer.PubString() == address {
+				c.userSigners[userID] = i
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("EC address %s of user %d is not one of wallet EC addresses", address, userID)
+		}
+	}
+
+	return nil
+
+}
+
+// GetECAddresses returns public server EC addresses of wallet
+func (c *Context) GetECAddresses() []string {
+
+	var res []string
+	for _, signer := range c.signers {
+		res = append(res, signer.PubString())
 	}
+	return res
 
-	return &Context{ECAddress}, nil
+}
+
+// payer returns signer of EC address to pay cost: user's own address if set, otherwise (or as fallback, if enabled) server address.
+// Like server addresses, user's address is used only if its balance stays >= minBalance after paying.
+func (c *Context) payer(userID int, userEC *factom.ECAddress, cost int64) Signer {
+
+	if userEC == nil {
+		return c.selectEC(userID, cost)
+	}
+
+	balance, err := factom.GetECBalance(userEC.PubString())
+	if err != nil {
+		log.Error("Can not fetch balance of EC address ", userEC.PubString(), ": ", err)
+	} else if balance >= cost+c.minBalance {
+		return NewLocalSigner(userEC)
+	}
+
+	if !c.userFallback {
+		return nil
+	}
+
+	log.Warn("EC address ", userEC.PubString(), " of user balance is below ", cost+c.minBalance, " EC, using server EC address")
+
+	return c.selectEC(userID, cost)
 
 }
 
-func (c *Context) GetEC() *factom.ECAddress {
-	return c.ec
+// selectEC returns signer of server EC address to pay cost according to wallet strategy.
+// Addresses with balance below cost + minBalance are skipped.
+func (c *Context) selectEC(userID int, cost int64) Signer {
+
+	n := len(c.signers)
+	start := 0
+
+	if n == 0 {
+		return nil
+	}
+
+	switch c.strategy {
+	case StrategyBalance:
+		var best Signer
+		var bestBalance int64
+		for _, signer := range c.signers {
+			balance := c.balance(signer.PubString())
+			if balance >= cost+c.minBalance && balance > bestBalance {
+				best = signer
+				bestBalance = balance
+			}
+		}
+		return best
+	case StrategyUser:
+		// dedicated address of user, next ones are used as failover
+		if i, ok := c.userSigners[userID]; ok {
+			start = i
+			break
+		}
+		// users without dedicated address are paid in turn
+		fallthrough
+	default:
+		start = int((atomic.AddUint32(&c.next, 1) - 1) % uint32(n))
+	}
+
+	for i := 0; i < n; i++ {
+		signer := c.signers[(start+i)%n]
+		if c.checkBalance(signer.PubString(), cost) {
+			return signer
+		}
+		log.Warn("EC address ", signer.PubString(), " balance is below ", cost+c.minBalance, " EC, skipping")
+	}
+
+	return nil
+
 }
 
-func (c *Context) checkBalance(cost int8) bool {
+// checkBalance returns true if cached balance of server EC address is enough to pay cost
+func (c *Context) checkBalance(address string, cost int64) bool {
 
-	balance, _ := factom.GetECBalance(c.ec.PubString())
-	if balance < int64(cost) {
+	balance := c.balance(address)
+	if balance < cost+c.minBalance {
 		return false
 	}
 
@@ -54,28 +326,55 @@ func (c *Context) checkBalance(cost int8) bool {
 
 }
 
-func (c *Context) CommitRevealEntry(entry *factom.Entry) (string, error) {
+// CommitEntry commits entry using user's EC address or server EC address selected for user & returns commit.
+// If entry was already committed, commit with empty txid is returned without error.
+func (c *Context) CommitEntry(entry *factom.Entry, userID int, userEC *factom.ECAddress) (*Commit, error) {
 
 	// calculate entry cost
 	cost, err := factom.EntryCost(entry)
 	if err != nil {
 		log.Error("Can not calculate Entry Cost")
-		return "", err
+		return nil, err
 	}
 
-	// check if EC balance enought for tx
-	if res := c.checkBalance(cost); res == false {
+	// select EC address with balance enought for tx
+	signer := c.payer(userID, userEC, int64(cost))
+	if signer == nil {
 		err = fmt.Errorf("Not enough Entry Credits to create entry")
 		log.Error(err)
-		return "", err
+		return nil, err
 	}
 
-	// commit+reveal entry
-	_, err = factom.CommitEntry(entry, c.GetEC())
+	commit := &Commit{ECAddress: signer.PubString(), Cost: int64(cost)}
+
+	req, err := composeEntryCommit(entry, signer)
 	if err != nil {
 		log.Error(err)
-		return "", err
+		return nil, err
 	}
+
+	commit.TxID, err = sendCommit(req)
+	if IsAlreadyCommitted(err) {
+		log.Debug("Entry ", entry.Hash(), " already committed")
+		commit.Cost = 0
+		return commit, nil
+	}
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	c.spend(commit.ECAddress, commit.Cost)
+
+	return commit, nil
+
+}
+
+// RevealEntry reveals committed entry & returns entry hash.
+// factomd accepts reveal of entry, that was already revealed, as a new one ("Entry Reveal Success"),
+// so repeated reveal needs no special handling.
+func (c *Context) RevealEntry(entry *factom.Entry) (string, error) {
+
 	resp, err := factom.RevealEntry(entry)
 	if err != nil {
 		log.Error(err)
@@ -86,24 +385,54 @@ func (c *Context) CommitRevealEntry(entry *factom.Entry) (string, error) {
 
 }
 
-func (c *Context) CommitRevealChain(chain *factom.Chain) (string, error) {
+// CommitChain commits chain using user's EC address or server EC address selected for user & returns commit.
+// If chain was already committed, commit with empty txid is returned without error.
+func (c *Context) CommitChain(chain *factom.Chain, userID int, userEC *factom.ECAddress) (*Commit, error) {
 
 	// calculate entry cost
 	cost, err := factom.EntryCost(chain.FirstEntry)
 	if err != nil {
 		log.Error("Can not calculate Entry Cost")
-		return "", err
+		return nil, err
 	}
 
-	// check if EC balance enought for tx
-	if res := c.checkBalance(cost + ChainECCost); res == false {
+	// select EC address with balance enought for tx
+	signer := c.payer(userID, userEC, int64(cost)+ChainECCost)
+	if signer == nil {
 		err = fmt.Errorf("Not enough Entry Credits to create chain")
 		log.Error(err)
-		return "", err
+		return nil, err
+	}
+
+	commit := &Commit{ECAddress: signer.PubString(), Cost: int64(cost) + ChainECCost}
+
+	req, err := composeChainCommit(chain, signer)
+	if err != nil {
+		log.Error(err)
+		return nil, err
+	}
+
+	commit.TxID, err = sendCommit(req)
+	if IsAlreadyCommitted(err) {
+		log.Debug("Chain ", chain.ChainID, " already committed")
+		commit.Cost = 0
+		return commit, nil
+	}
+	if err != nil {
+		log.Error(err)
+		return nil, err
 	}
 
-	// commit chain
-	_, err = factom.CommitChain(chain, c.GetEC())
+	c.spend(commit.ECAddress, commit.Cost)
+
+	return commit, nil
+
+}
+
+// RevealChain reveals committed chain & returns hash of its first entry.
+// Like RevealEntry(), repeated reveal is accepted by factomd.
+func (c *Context) RevealChain(chain *factom.Chain) (string, error) {
+
 	resp, err := factom.RevealChain(chain)
 	if err != nil {
 		log.Error(err)
@@ -113,3 +442,12 @@ func (c *Context) CommitRevealChain(chain *factom.Chain) (string, error) {
 	return resp, nil
 
 }
+
+// IsAlreadyCommitted returns true if factomd rejected commit with "Repeated Commit" error, because it was committed before
+func IsAlreadyCommitted(err error) bool {
+
+	jsonErr, ok := err.(*factom.JSONError)
+
+	return ok && (jsonErr.Code == RepeatedCommitCode || jsonErr.Message == RepeatedCommitMessage)
+
+}